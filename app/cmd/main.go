@@ -1,11 +1,50 @@
 package main
 
 import (
+    "context"
+    "fmt"
+    "os"
+    "os/signal"
+    "syscall"
+
     "github.com/aws/aws-lambda-go/lambda"
+    "weather-lambda/internal/cache"
     "weather-lambda/internal/handler"
+    "weather-lambda/internal/log"
 )
 
+// version is injected at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
 func main() {
+    handler.Version = version
+
+    // Refuse to start with configuration the handler cannot serve
+    if err := handler.ValidateConfig(); err != nil {
+        log.Error(fmt.Sprintf("Invalid configuration: %v", err))
+        os.Exit(1)
+    }
+
+    // Exercise the upstream, store and cache once before serving traffic
+    if err := handler.SelfTest(context.Background()); err != nil && os.Getenv("SELF_TEST_STRICT") == "true" {
+        log.Error(fmt.Sprintf("Self-test failed: %v", err))
+        os.Exit(1)
+    }
+
+    // Stop background work when the runtime shuts the container down
+    stop := make(chan struct{})
+    go func() {
+        signals := make(chan os.Signal, 1)
+        signal.Notify(signals, syscall.SIGTERM)
+        <-signals
+        close(stop)
+    }()
+    cache.StartStatsLogger(stop)
+
+    // Function URLs in RESPONSE_STREAM mode need the streaming handler
+    if os.Getenv("LAMBDA_INVOKE_MODE") == "stream" {
+        lambda.Start(handler.HandleStream)
+        return
+    }
     lambda.Start(handler.HandleRequest)
 }
-
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+const defaultBucketSize = 5 * time.Minute
+
+// now is the clock used for bucket alignment; tests can replace it.
+var now = time.Now
+
+// alignTTL shortens ttl so the entry expires on a wall-clock bucket boundary,
+// letting every container and any CDN in front expire entries together. It
+// picks the last boundary within ttl, or the next one when none falls inside.
+// It is enabled by the cache-aligned feature, with CACHE_BUCKET_SECONDS
+// (default 300) setting the bucket size.
+func alignTTL(ttl time.Duration) time.Duration {
+	if !features.Enabled("cache-aligned") {
+		return ttl
+	}
+
+	bucket := defaultBucketSize
+	if seconds, err := strconv.Atoi(os.Getenv("CACHE_BUCKET_SECONDS")); err == nil && seconds > 0 {
+		bucket = time.Duration(seconds) * time.Second
+	}
+
+	current := now()
+	expiry := current.Add(ttl).Truncate(bucket)
+	if !expiry.After(current) {
+		expiry = current.Truncate(bucket).Add(bucket)
+	}
+	return expiry.Sub(current)
+}
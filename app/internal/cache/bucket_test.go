@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"weather-lambda/internal/feature"
+)
+
+func TestAlignTTL(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		enabled bool
+		seconds string
+		at      time.Time
+		ttl     time.Duration
+		want    time.Duration
+	}{
+		{"disabled", false, "", base.Add(time.Minute), 10 * time.Minute, 10 * time.Minute},
+		{"last boundary within the TTL", true, "", base.Add(time.Minute), 10 * time.Minute, 9 * time.Minute},
+		{"TTL ending on a boundary", true, "", base, 10 * time.Minute, 10 * time.Minute},
+		{"no boundary within the TTL", true, "", base.Add(time.Minute), time.Minute, 4 * time.Minute},
+		{"custom bucket size", true, "60", base.Add(90 * time.Second), 2 * time.Minute, 90 * time.Second},
+		{"invalid bucket size uses the default", true, "soon", base.Add(time.Minute), 10 * time.Minute, 9 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			originalFeatures := features
+			if tt.enabled {
+				features = feature.Parse("cache-aligned")
+			} else {
+				features = feature.Parse("")
+			}
+			t.Cleanup(func() { features = originalFeatures })
+			t.Setenv("CACHE_BUCKET_SECONDS", tt.seconds)
+			original := now
+			now = func() time.Time { return tt.at }
+			t.Cleanup(func() { now = original })
+
+			got := alignTTL(tt.ttl)
+			if got != tt.want {
+				t.Errorf("alignTTL(%v) = %v, want %v", tt.ttl, got, tt.want)
+			}
+			if tt.enabled {
+				if expiry := tt.at.Add(got); !expiry.Equal(expiry.Truncate(time.Minute)) {
+					t.Errorf("expiry %v is not on a boundary", expiry)
+				}
+			}
+		})
+	}
+}
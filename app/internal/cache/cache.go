@@ -2,25 +2,90 @@ package cache
 
 import (
 	"fmt"
+	"math"
+	"os"
+	"strconv"
 	"time"
 	"weather-lambda/internal/log"
+	"weather-lambda/internal/metrics"
 
 	"github.com/patrickmn/go-cache"
 )
 
-var c = cache.New(5*time.Minute, 10*time.Minute)
+const defaultTTL = 5 * time.Minute
 
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl time.Duration)
+	Len() int
+}
+
+var c = newCache()
+
+// newCache bounds the cache when CACHE_MAX_ENTRIES or CACHE_NAMESPACE_CAPS
+// is set; namespace caps need the LRU's accounting, even without an overall
+// bound.
+func newCache() Cache {
+	caps, err := parseNamespaceCaps(os.Getenv("CACHE_NAMESPACE_CAPS"))
+	if err != nil {
+		log.Error(fmt.Sprintf("Ignoring namespace caps: %v", err))
+	}
+
+	maxEntries, err := strconv.Atoi(os.Getenv("CACHE_MAX_ENTRIES"))
+	if err != nil || maxEntries <= 0 {
+		if caps == nil {
+			return &memoryCache{cache.New(defaultTTL, 10*time.Minute)}
+		}
+		maxEntries = math.MaxInt
+	}
+	return NewLRU(maxEntries).WithNamespaceCaps(caps)
+}
+
+// memoryCache adapts go-cache, which has no bound on entry count.
+type memoryCache struct {
+	*cache.Cache
+}
+
+func (m *memoryCache) Set(key string, value interface{}, ttl time.Duration) {
+	m.Cache.Set(key, value, ttl)
+}
+
+func (m *memoryCache) Len() int {
+	return m.ItemCount()
+}
+
+// SetCache stores value under key, subject to CACHE_WRITE_POLICY.
 func SetCache(key string, value interface{}) {
 	log.Info(fmt.Sprintf("Setting cache for key: %s", key))
-	c.Set(key, value, cache.DefaultExpiration)
+	writes.write(key, value, func(value interface{}) {
+		setEntry(key, value, alignTTL(health.effectiveTTL(defaultTTL)))
+	})
+}
+
+// SetCacheFor stores value under key for exactly ttl. It bypasses the write
+// policy, TTL floor and stale retention, for short-lived entries that must
+// not outlive their TTL.
+func SetCacheFor(key string, value interface{}, ttl time.Duration) {
+	log.Info(fmt.Sprintf("Setting cache for key: %s for %s", key, ttl))
+	c.Set(key, value, ttl)
+}
+
+// Lookup returns an entry stored with SetCacheFor without counting a hit or
+// miss, for bookkeeping entries that are not cached readings.
+func Lookup(key string) (interface{}, bool) {
+	return c.Get(key)
 }
 
 func GetCache(key string) (interface{}, bool) {
-	data, found := c.Get(key)
+	data, found := getEntry(key)
 	if found {
 		log.Info(fmt.Sprintf("Cache hit for key: %s", key))
+		metrics.CacheHits.Inc()
+		hits.Add(1)
 	} else {
 		log.Info(fmt.Sprintf("Cache miss for key: %s", key))
+		metrics.CacheMisses.Inc()
+		misses.Add(1)
 	}
 	return data, found
 }
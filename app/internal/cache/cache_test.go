@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLookupIsNotMetered(t *testing.T) {
+	SetCacheFor("lookup-test:present", 1, time.Minute)
+
+	before := CurrentStats()
+	if value, found := Lookup("lookup-test:present"); !found || value != 1 {
+		t.Errorf("Lookup = %v, %v; want 1, true", value, found)
+	}
+	if _, found := Lookup("lookup-test:absent"); found {
+		t.Errorf("Lookup found an absent key")
+	}
+	after := CurrentStats()
+	if after.Hits != before.Hits || after.Misses != before.Misses {
+		t.Errorf("Lookup changed hits %d->%d, misses %d->%d", before.Hits, after.Hits, before.Misses, after.Misses)
+	}
+
+	GetCache("lookup-test:present")
+	if CurrentStats().Hits != after.Hits+1 {
+		t.Errorf("GetCache did not count a hit")
+	}
+}
@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// Key derives a stable cache key for a city so that differently escaped or
+// cased spellings of the same name share one entry.
+func Key(city string) string {
+	return NamespacedKey("weather", city)
+}
+
+func NamespacedKey(namespace string, city string) string {
+	sum := sha256.Sum256([]byte(NormalizeCity(city)))
+	return namespace + ":" + hex.EncodeToString(sum[:8])
+}
+
+func NormalizeCity(city string) string {
+	if unescaped, err := url.QueryUnescape(city); err == nil {
+		city = unescaped
+	}
+	return strings.ToLower(strings.Join(strings.Fields(city), " "))
+}
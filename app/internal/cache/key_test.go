@@ -0,0 +1,31 @@
+package cache
+
+import "testing"
+
+func TestKeyEquivalentSpellings(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"query escaped", "São Paulo", "S%C3%A3o+Paulo"},
+		{"path escaped", "São Paulo", "S%C3%A3o%20Paulo"},
+		{"case", "new york", "New York"},
+		{"whitespace", "  New   York ", "New York"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if Key(tt.a) != Key(tt.b) {
+				t.Errorf("Key(%q) = %s, Key(%q) = %s; want equal", tt.a, Key(tt.a), tt.b, Key(tt.b))
+			}
+		})
+	}
+}
+
+func TestKeyDistinctCities(t *testing.T) {
+	if Key("Paris") == Key("London") {
+		t.Errorf("Paris and London share key %s", Key("Paris"))
+	}
+	if NamespacedKey("weather", "Paris") == NamespacedKey("forecast", "Paris") {
+		t.Errorf("namespaces share a key for the same city")
+	}
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is a bounded cache that evicts the least recently used entry once
+// maxEntries is reached. Expired entries are dropped when they are read.
+// A namespace with a cap evicts its own least recently used entry once it
+// holds that many, so a flood of one kind of key cannot evict the others.
+type LRU struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+	evictions  uint64
+
+	namespaceCaps   map[string]int
+	namespaceCounts map[string]int
+}
+
+type lruEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+func NewLRU(maxEntries int) *LRU {
+	return &LRU{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+
+		namespaceCounts: make(map[string]int),
+	}
+}
+
+// WithNamespaceCaps bounds the entries of each named namespace.
+func (l *LRU) WithNamespaceCaps(caps map[string]int) *LRU {
+	l.namespaceCaps = caps
+	return l
+}
+
+func (l *LRU) Get(key string) (interface{}, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		l.remove(element)
+		return nil, false
+	}
+
+	l.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (l *LRU) Set(key string, value interface{}, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if element, ok := l.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		l.order.MoveToFront(element)
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	namespace := namespaceOf(key)
+	l.namespaceCounts[namespace]++
+	if limit, ok := l.namespaceCaps[namespace]; ok && l.namespaceCounts[namespace] > limit {
+		l.remove(l.oldestIn(namespace))
+		l.evictions++
+	}
+	for l.order.Len() > l.maxEntries {
+		l.remove(l.order.Back())
+		l.evictions++
+	}
+}
+
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+func (l *LRU) Evictions() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.evictions
+}
+
+// oldestIn returns the least recently used entry in a namespace. It is only
+// called when the namespace is over its cap, so one is always found.
+func (l *LRU) oldestIn(namespace string) *list.Element {
+	for element := l.order.Back(); element != nil; element = element.Prev() {
+		if namespaceOf(element.Value.(*lruEntry).key) == namespace {
+			return element
+		}
+	}
+	return nil
+}
+
+func (l *LRU) remove(element *list.Element) {
+	key := element.Value.(*lruEntry).key
+	l.order.Remove(element)
+	delete(l.entries, key)
+
+	namespace := namespaceOf(key)
+	if l.namespaceCounts[namespace]--; l.namespaceCounts[namespace] == 0 {
+		delete(l.namespaceCounts, namespace)
+	}
+}
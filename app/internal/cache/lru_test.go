@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUEvictsAtLimit(t *testing.T) {
+	l := NewLRU(2)
+	l.Set("a", 1, time.Minute)
+	l.Set("b", 2, time.Minute)
+	l.Set("c", 3, time.Minute)
+
+	if l.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", l.Len())
+	}
+	if _, ok := l.Get("a"); ok {
+		t.Errorf("oldest entry a survived")
+	}
+	if l.Evictions() != 1 {
+		t.Errorf("Evictions = %d, want 1", l.Evictions())
+	}
+}
+
+func TestLRURecentlyUsedSurvives(t *testing.T) {
+	l := NewLRU(2)
+	l.Set("a", 1, time.Minute)
+	l.Set("b", 2, time.Minute)
+	l.Get("a")
+	l.Set("c", 3, time.Minute)
+
+	if _, ok := l.Get("a"); !ok {
+		t.Errorf("recently read entry a was evicted")
+	}
+	if _, ok := l.Get("b"); ok {
+		t.Errorf("least recently used entry b survived")
+	}
+}
+
+func TestLRUHonorsTTL(t *testing.T) {
+	l := NewLRU(2)
+	l.Set("a", 1, -time.Second)
+
+	if _, ok := l.Get("a"); ok {
+		t.Errorf("expired entry was returned")
+	}
+	if l.Len() != 0 {
+		t.Errorf("expired entry was not dropped on read; Len = %d", l.Len())
+	}
+}
+
+func TestLRUOverwriteKeepsOneEntry(t *testing.T) {
+	l := NewLRU(2)
+	l.Set("a", 1, time.Minute)
+	l.Set("a", 2, time.Minute)
+
+	if got, _ := l.Get("a"); got != 2 {
+		t.Errorf("Get(a) = %v, want 2", got)
+	}
+	if l.Len() != 1 {
+		t.Errorf("Len = %d, want 1", l.Len())
+	}
+}
+
+func TestLRUNamespaceCapEvictsWithinNamespace(t *testing.T) {
+	l := NewLRU(10).WithNamespaceCaps(map[string]int{"idem": 2})
+	l.Set("weather:a", 1, time.Minute)
+	l.Set("weather:b", 2, time.Minute)
+	for _, key := range []string{"idem:1", "idem:2", "idem:3", "idem:4"} {
+		l.Set(key, key, time.Minute)
+	}
+
+	for _, key := range []string{"weather:a", "weather:b", "idem:3", "idem:4"} {
+		if _, ok := l.Get(key); !ok {
+			t.Errorf("%s was evicted", key)
+		}
+	}
+	for _, key := range []string{"idem:1", "idem:2"} {
+		if _, ok := l.Get(key); ok {
+			t.Errorf("%s survived past the idem cap", key)
+		}
+	}
+	if l.Evictions() != 2 {
+		t.Errorf("Evictions = %d, want 2", l.Evictions())
+	}
+}
+
+func TestLRUNamespaceCapCountsSuffixedNamespaces(t *testing.T) {
+	l := NewLRU(10).WithNamespaceCaps(map[string]int{"weather": 2})
+	l.Set("weather:a", 1, time.Minute)
+	l.Set("weather-aq:b", 2, time.Minute)
+	l.Set("weather-moon:c", 3, time.Minute)
+
+	if _, ok := l.Get("weather:a"); ok {
+		t.Errorf("weather:a survived; suffixed namespaces should share the weather cap")
+	}
+	if l.Len() != 2 {
+		t.Errorf("Len = %d, want 2", l.Len())
+	}
+}
+
+func TestLRUNamespaceCapFreesRoomOnRemoval(t *testing.T) {
+	l := NewLRU(10).WithNamespaceCaps(map[string]int{"idem": 1})
+	l.Set("idem:1", 1, -time.Second)
+	l.Get("idem:1")
+	l.Set("idem:2", 2, time.Minute)
+
+	if l.Evictions() != 0 {
+		t.Errorf("Evictions = %d, want 0 after the expired entry was dropped", l.Evictions())
+	}
+}
+
+func TestParseNamespaceCaps(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    map[string]int
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"error=100, Requests = 1000", map[string]int{"error": 100, "requests": 1000}, false},
+		{"error=100,", map[string]int{"error": 100}, false},
+		{"error", nil, true},
+		{"error=0", nil, true},
+		{"error=many", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseNamespaceCaps(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseNamespaceCaps(%q) err = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("parseNamespaceCaps(%q) = %v, want %v", tt.value, got, tt.want)
+			continue
+		}
+		for namespace, limit := range tt.want {
+			if got[namespace] != limit {
+				t.Errorf("parseNamespaceCaps(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// namespaceOf returns the base namespace of a key, so "weather-aq:…" and
+// "weather:…" share the weather cap.
+func namespaceOf(key string) string {
+	namespace, _, _ := strings.Cut(key, ":")
+	base, _, _ := strings.Cut(namespace, "-")
+	return base
+}
+
+// parseNamespaceCaps reads CACHE_NAMESPACE_CAPS, a comma-separated list of
+// namespace=maxEntries pairs such as "error=100,requests=1000". Namespaces
+// without a cap share whatever room the overall bound leaves.
+func parseNamespaceCaps(value string) (map[string]int, error) {
+	var caps map[string]int
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		namespace, limit, ok := strings.Cut(pair, "=")
+		maxEntries, err := strconv.Atoi(strings.TrimSpace(limit))
+		if !ok || err != nil || maxEntries <= 0 {
+			return nil, fmt.Errorf("CACHE_NAMESPACE_CAPS: invalid entry %q", pair)
+		}
+		if caps == nil {
+			caps = map[string]int{}
+		}
+		caps[strings.ToLower(strings.TrimSpace(namespace))] = maxEntries
+	}
+	return caps, nil
+}
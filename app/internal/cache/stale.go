@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+	"weather-lambda/internal/feature"
+	"weather-lambda/internal/log"
+)
+
+// staleRetention is how long past its TTL an entry is kept for serving while
+// the upstream is degraded.
+const staleRetention = time.Hour
+
+// staleEntry wraps a value kept beyond its TTL under the serve-stale feature.
+type staleEntry struct {
+	value      interface{}
+	freshUntil time.Time
+}
+
+// Feature flags are resolved once per container at cold start
+var features = feature.Current()
+
+func serveStaleWhenOpen() bool {
+	return features.Enabled("serve-stale")
+}
+
+// setEntry stores value for ttl. With the serve-stale feature it is
+// retained for staleRetention longer, with ttl still marking it fresh.
+func setEntry(key string, value interface{}, ttl time.Duration) {
+	if !serveStaleWhenOpen() {
+		c.Set(key, value, ttl)
+		return
+	}
+	c.Set(key, staleEntry{value: value, freshUntil: time.Now().Add(ttl)}, ttl+staleRetention)
+}
+
+// getEntry returns a fresh value, or an expired one while the upstream error
+// rate has the circuit open, so an outage does not turn cache hits into
+// failed fetches.
+func getEntry(key string) (interface{}, bool) {
+	data, found := c.Get(key)
+	entry, ok := data.(staleEntry)
+	if !found || !ok {
+		return data, found
+	}
+
+	if time.Now().Before(entry.freshUntil) {
+		return entry.value, true
+	}
+	if health.isDegraded() {
+		log.Info(fmt.Sprintf("Serving stale cache entry while upstream is degraded: %s", key))
+		return entry.value, true
+	}
+	return nil, false
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"weather-lambda/internal/feature"
+)
+
+func TestServeStaleWhileDegraded(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   string
+		degraded  bool
+		wantFound bool
+	}{
+		{"degraded", "serve-stale", true, true},
+		{"healthy", "serve-stale", false, false},
+		{"feature off", "", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			originalFeatures, originalHealth := features, health
+			features, health = feature.Parse(tt.enabled), &upstreamHealth{}
+			t.Cleanup(func() { features, health = originalFeatures, originalHealth })
+
+			for i := 0; i < minWindowSamples; i++ {
+				RecordUpstreamResult(errorIf(tt.degraded))
+			}
+
+			key := "stale-test:" + tt.name
+			setEntry(key, "reading", time.Millisecond)
+			time.Sleep(5 * time.Millisecond)
+
+			value, found := getEntry(key)
+			if found != tt.wantFound {
+				t.Fatalf("found = %v, want %v", found, tt.wantFound)
+			}
+			if found && value != "reading" {
+				t.Errorf("value = %v, want the unwrapped reading", value)
+			}
+		})
+	}
+}
+
+func TestFreshEntryIsUnwrapped(t *testing.T) {
+	originalFeatures := features
+	features = feature.Parse("serve-stale")
+	t.Cleanup(func() { features = originalFeatures })
+
+	setEntry("stale-test:fresh", "reading", time.Minute)
+	if value, found := getEntry("stale-test:fresh"); !found || value != "reading" {
+		t.Errorf("getEntry = %v, %v; want the fresh reading", value, found)
+	}
+}
+
+func errorIf(failed bool) error {
+	if failed {
+		return errTest
+	}
+	return nil
+}
+
+var errTest = errors.New("upstream failed")
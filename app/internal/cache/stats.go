@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"weather-lambda/internal/log"
+)
+
+var (
+	hits   atomic.Uint64
+	misses atomic.Uint64
+
+	statsOnce sync.Once
+)
+
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Size      int
+	Evictions uint64
+}
+
+func (s Stats) HitRatio() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+	return 0
+}
+
+func CurrentStats() Stats {
+	stats := Stats{Hits: hits.Load(), Misses: misses.Load(), Size: c.Len()}
+	if evicting, ok := c.(interface{ Evictions() uint64 }); ok {
+		stats.Evictions = evicting.Evictions()
+	}
+	return stats
+}
+
+// StartStatsLogger logs cache stats every CACHE_STATS_INTERVAL_SECONDS until
+// stop is closed. It starts at most once per process and does nothing when
+// the interval is unset.
+func StartStatsLogger(stop <-chan struct{}) {
+	seconds, err := strconv.Atoi(os.Getenv("CACHE_STATS_INTERVAL_SECONDS"))
+	if err != nil || seconds <= 0 {
+		return
+	}
+
+	statsOnce.Do(func() {
+		ticker := time.NewTicker(time.Duration(seconds) * time.Second)
+		go func() {
+			defer ticker.Stop()
+			logStats(ticker.C, stop, log.Info)
+		}()
+	})
+}
+
+// logStats emits a stats line on every tick until stop is closed.
+func logStats(ticks <-chan time.Time, stop <-chan struct{}, emit func(string)) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticks:
+			emit(statsLine(CurrentStats()))
+		}
+	}
+}
+
+func statsLine(stats Stats) string {
+	return fmt.Sprintf("Cache stats: hit_ratio=%.2f hits=%d misses=%d size=%d evictions=%d",
+		stats.HitRatio(), stats.Hits, stats.Misses, stats.Size, stats.Evictions)
+}
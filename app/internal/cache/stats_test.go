@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogStatsEmitsOnTick(t *testing.T) {
+	ticks := make(chan time.Time)
+	stop := make(chan struct{})
+	lines := make(chan string, 1)
+	done := make(chan struct{})
+	go func() {
+		logStats(ticks, stop, func(line string) { lines <- line })
+		close(done)
+	}()
+
+	ticks <- time.Now()
+	select {
+	case line := <-lines:
+		if !strings.HasPrefix(line, "Cache stats: hit_ratio=") || !strings.Contains(line, "evictions=") {
+			t.Errorf("unexpected stats line %q", line)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no stats line after a tick")
+	}
+
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("logger did not stop")
+	}
+}
+
+func TestStatsLine(t *testing.T) {
+	got := statsLine(Stats{Hits: 3, Misses: 1, Size: 2, Evictions: 5})
+	want := "Cache stats: hit_ratio=0.75 hits=3 misses=1 size=2 evictions=5"
+	if got != want {
+		t.Errorf("statsLine = %q, want %q", got, want)
+	}
+}
+
+func TestStartStatsLoggerUnset(t *testing.T) {
+	t.Setenv("CACHE_STATS_INTERVAL_SECONDS", "")
+	StartStatsLogger(make(chan struct{}))
+
+	started := true
+	statsOnce.Do(func() { started = false })
+	if started {
+		t.Errorf("logger started with the interval unset")
+	}
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"weather-lambda/internal/log"
+)
+
+const (
+	errorWindow      = time.Minute
+	minWindowSamples = 5
+	defaultErrorRate = 0.5
+)
+
+// upstreamHealth tracks upstream outcomes over a sliding window so the cache
+// can hold entries longer while the upstream is failing.
+type upstreamHealth struct {
+	mu       sync.Mutex
+	outcomes []upstreamOutcome
+	degraded bool
+}
+
+type upstreamOutcome struct {
+	at     time.Time
+	failed bool
+}
+
+var health = &upstreamHealth{}
+
+// RecordUpstreamResult feeds the outcome of an upstream fetch into the
+// error-rate window used by the TTL floor.
+func RecordUpstreamResult(err error) {
+	health.record(time.Now(), err != nil)
+}
+
+func (h *upstreamHealth) record(now time.Time, failed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.outcomes = append(h.outcomes, upstreamOutcome{at: now, failed: failed})
+	h.prune(now)
+
+	degraded := h.errorRate() >= errorRateThreshold()
+	if degraded != h.degraded {
+		h.degraded = degraded
+		if degraded {
+			log.Warn("Upstream error rate is high, applying the cache TTL floor")
+		} else {
+			log.Info("Upstream error rate recovered, removing the cache TTL floor")
+		}
+	}
+}
+
+func (h *upstreamHealth) prune(now time.Time) {
+	cutoff := now.Add(-errorWindow)
+	i := 0
+	for i < len(h.outcomes) && h.outcomes[i].at.Before(cutoff) {
+		i++
+	}
+	h.outcomes = h.outcomes[i:]
+}
+
+func (h *upstreamHealth) errorRate() float64 {
+	if len(h.outcomes) < minWindowSamples {
+		return 0
+	}
+	failed := 0
+	for _, outcome := range h.outcomes {
+		if outcome.failed {
+			failed++
+		}
+	}
+	return float64(failed) / float64(len(h.outcomes))
+}
+
+// effectiveTTL raises ttl to CACHE_TTL_FLOOR_SECONDS while the upstream is degraded.
+func (h *upstreamHealth) effectiveTTL(ttl time.Duration) time.Duration {
+	if !h.isDegraded() {
+		return ttl
+	}
+
+	seconds, err := strconv.Atoi(os.Getenv("CACHE_TTL_FLOOR_SECONDS"))
+	if err != nil || seconds <= 0 {
+		return ttl
+	}
+	if floor := time.Duration(seconds) * time.Second; floor > ttl {
+		log.Info(fmt.Sprintf("Applying cache TTL floor of %s", floor))
+		return floor
+	}
+	return ttl
+}
+
+// isDegraded reports whether the upstream error rate is over the threshold,
+// which acts as an open circuit for the cache.
+func (h *upstreamHealth) isDegraded() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.prune(time.Now())
+	return h.degraded && h.errorRate() >= errorRateThreshold()
+}
+
+func errorRateThreshold() float64 {
+	rate, err := strconv.ParseFloat(os.Getenv("CACHE_TTL_FLOOR_ERROR_RATE"), 64)
+	if err != nil || rate <= 0 || rate > 1 {
+		return defaultErrorRate
+	}
+	return rate
+}
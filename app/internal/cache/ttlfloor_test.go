@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEffectiveTTL(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		total    int
+		floor    string
+		want     time.Duration
+	}{
+		{"healthy", 0, 10, "600", time.Minute},
+		{"high error rate", 8, 10, "600", 10 * time.Minute},
+		{"at the threshold", 5, 10, "600", 10 * time.Minute},
+		{"too few samples", 3, 3, "600", time.Minute},
+		{"floor unset", 10, 10, "", time.Minute},
+		{"floor below ttl", 10, 10, "30", time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CACHE_TTL_FLOOR_SECONDS", tt.floor)
+			h := &upstreamHealth{}
+			now := time.Now()
+			for i := 0; i < tt.total; i++ {
+				h.record(now, i < tt.failures)
+			}
+			if got := h.effectiveTTL(time.Minute); got != tt.want {
+				t.Errorf("effectiveTTL = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTTLFloorReverts(t *testing.T) {
+	t.Setenv("CACHE_TTL_FLOOR_SECONDS", "600")
+	h := &upstreamHealth{}
+	now := time.Now()
+	for i := 0; i < minWindowSamples; i++ {
+		h.record(now, true)
+	}
+	if got := h.effectiveTTL(time.Minute); got != 10*time.Minute {
+		t.Fatalf("effectiveTTL while failing = %v, want the floor", got)
+	}
+
+	for i := 0; i < 2*minWindowSamples; i++ {
+		h.record(now, false)
+	}
+	if got := h.effectiveTTL(time.Minute); got != time.Minute {
+		t.Errorf("effectiveTTL after recovery = %v, want %v", got, time.Minute)
+	}
+}
+
+func TestErrorWindowSlides(t *testing.T) {
+	h := &upstreamHealth{}
+	old := time.Now().Add(-2 * errorWindow)
+	for i := 0; i < minWindowSamples; i++ {
+		h.record(old, true)
+	}
+	if !h.degraded {
+		t.Fatalf("not degraded after %d failures", minWindowSamples)
+	}
+	if h.isDegraded() {
+		t.Errorf("failures older than the window still count")
+	}
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultWriteWindow = time.Second
+
+// coalescer implements CACHE_WRITE_POLICY=write-back. The first write for a
+// key goes straight to the cache; further writes within the window are held
+// and only the latest is applied when the window closes. The window is far
+// shorter than any TTL, so a held value always lands before the entry expires.
+type coalescer struct {
+	mu      sync.Mutex
+	window  time.Duration
+	pending map[string]*pendingWrite
+}
+
+type pendingWrite struct {
+	value interface{}
+	held  bool
+}
+
+var writes = newCoalescer()
+
+func newCoalescer() *coalescer {
+	if os.Getenv("CACHE_WRITE_POLICY") != "write-back" {
+		return nil
+	}
+
+	window := defaultWriteWindow
+	if millis, err := strconv.Atoi(os.Getenv("CACHE_WRITE_WINDOW_MS")); err == nil && millis > 0 {
+		window = time.Duration(millis) * time.Millisecond
+	}
+	return &coalescer{window: window, pending: make(map[string]*pendingWrite)}
+}
+
+// write applies value now if no window is open for key, otherwise holds it.
+// A nil coalescer is write-through and always writes immediately.
+func (w *coalescer) write(key string, value interface{}, set func(interface{})) {
+	if w == nil {
+		set(value)
+		return
+	}
+
+	w.mu.Lock()
+	if write, ok := w.pending[key]; ok {
+		write.value = value
+		write.held = true
+		w.mu.Unlock()
+		return
+	}
+	w.pending[key] = &pendingWrite{}
+	w.mu.Unlock()
+
+	set(value)
+	time.AfterFunc(w.window, func() { w.flush(key, set) })
+}
+
+func (w *coalescer) flush(key string, set func(interface{})) {
+	w.mu.Lock()
+	write := w.pending[key]
+	delete(w.pending, key)
+	w.mu.Unlock()
+
+	if write != nil && write.held {
+		set(write.value)
+	}
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder collects the values a coalescer applies.
+type recorder struct {
+	mu     sync.Mutex
+	values []interface{}
+}
+
+func (r *recorder) set(value interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values = append(r.values, value)
+}
+
+func (r *recorder) applied() []interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]interface{}(nil), r.values...)
+}
+
+func TestWriteThroughAppliesEveryWrite(t *testing.T) {
+	var w *coalescer
+	r := &recorder{}
+	for i := 1; i <= 3; i++ {
+		w.write("key", i, r.set)
+	}
+	if got := r.applied(); !reflect.DeepEqual(got, []interface{}{1, 2, 3}) {
+		t.Errorf("applied = %v, want every write", got)
+	}
+}
+
+func TestWriteBackCoalesces(t *testing.T) {
+	w := &coalescer{window: 20 * time.Millisecond, pending: make(map[string]*pendingWrite)}
+	r := &recorder{}
+	for i := 1; i <= 3; i++ {
+		w.write("key", i, r.set)
+	}
+	if got := r.applied(); !reflect.DeepEqual(got, []interface{}{1}) {
+		t.Fatalf("applied within the window = %v, want only the first write", got)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(r.applied()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := r.applied(); !reflect.DeepEqual(got, []interface{}{1, 3}) {
+		t.Errorf("applied after the window = %v, want the first and latest writes", got)
+	}
+}
+
+func TestWriteBackSingleWriteIsNotRepeated(t *testing.T) {
+	w := &coalescer{window: 10 * time.Millisecond, pending: make(map[string]*pendingWrite)}
+	r := &recorder{}
+	w.write("key", 1, r.set)
+	time.Sleep(30 * time.Millisecond)
+
+	if got := r.applied(); !reflect.DeepEqual(got, []interface{}{1}) {
+		t.Errorf("applied = %v, want the single write once", got)
+	}
+}
+
+func TestNewCoalescerPolicy(t *testing.T) {
+	t.Setenv("CACHE_WRITE_POLICY", "write-through")
+	if newCoalescer() != nil {
+		t.Errorf("write-through built a coalescer")
+	}
+
+	t.Setenv("CACHE_WRITE_POLICY", "write-back")
+	t.Setenv("CACHE_WRITE_WINDOW_MS", "250")
+	if w := newCoalescer(); w == nil || w.window != 250*time.Millisecond {
+		t.Errorf("write-back coalescer = %+v, want a 250ms window", w)
+	}
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// payloadAttribute holds the gzipped JSON of a compressed reading
+const payloadAttribute = "Payload"
+
+func compressionEnabled() bool {
+	return features.Enabled("compress")
+}
+
+// compressItem stores a reading as a gzipped JSON payload. City and Time
+// stay plain attributes so the key and observation time remain queryable,
+// as does ExpiresAt so the table's TTL still sees it.
+func compressItem(data WeatherData) (map[string]*dynamodb.AttributeValue, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload bytes.Buffer
+	writer := gzip.NewWriter(&payload)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	item := map[string]*dynamodb.AttributeValue{
+		"City":           {S: aws.String(data.City)},
+		"Time":           {S: aws.String(data.Time)},
+		payloadAttribute: {B: payload.Bytes()},
+	}
+	if data.ExpiresAt != 0 {
+		item["ExpiresAt"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(data.ExpiresAt, 10))}
+	}
+	return item, nil
+}
+
+// unmarshalItem decodes a stored reading, decompressing it if it was saved
+// with the compress feature. Items of either form are read regardless of the
+// current setting, so the option can be switched on a populated table.
+func unmarshalItem(item map[string]*dynamodb.AttributeValue) (WeatherData, error) {
+	var data WeatherData
+	payload, ok := item[payloadAttribute]
+	if !ok || payload.B == nil {
+		err := dynamodbattribute.UnmarshalMap(item, &data)
+		return data, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(payload.B))
+	if err != nil {
+		return WeatherData{}, err
+	}
+	defer reader.Close()
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return WeatherData{}, err
+	}
+	err = json.Unmarshal(body, &data)
+	return data, err
+}
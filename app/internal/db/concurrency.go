@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+)
+
+var (
+	writeSlotsOnce sync.Once
+	writeSlots     chan struct{}
+)
+
+// acquireWrite waits for one of DB_MAX_CONCURRENCY write slots, or until ctx
+// is done. Writes are unbounded when the variable is unset or not positive.
+// The returned func releases the slot and must always be called.
+func acquireWrite(ctx context.Context) (func(), error) {
+	writeSlotsOnce.Do(func() {
+		if limit, err := strconv.Atoi(os.Getenv("DB_MAX_CONCURRENCY")); err == nil && limit > 0 {
+			writeSlots = make(chan struct{}, limit)
+		}
+	})
+	if writeSlots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case writeSlots <- struct{}{}:
+		return func() { <-writeSlots }, nil
+	case <-ctx.Done():
+		return func() {}, ctx.Err()
+	}
+}
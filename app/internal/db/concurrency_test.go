@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// slowPutter records the most writes it saw in flight at once.
+type slowPutter struct {
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (p *slowPutter) PutItemWithContext(aws.Context, *dynamodb.PutItemInput, ...request.Option) (*dynamodb.PutItemOutput, error) {
+	n := p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// limitWrites resets the process-wide write slots to limit for one test.
+func limitWrites(t *testing.T, limit string) {
+	t.Helper()
+	t.Setenv("DB_MAX_CONCURRENCY", limit)
+	writeSlotsOnce, writeSlots = sync.Once{}, nil
+	t.Cleanup(func() { writeSlotsOnce, writeSlots = sync.Once{}, nil })
+}
+
+func TestWriteConcurrencyIsBounded(t *testing.T) {
+	limitWrites(t, "2")
+	putter := &slowPutter{}
+	original := newPutter
+	newPutter = func(...*aws.Config) itemPutter { return putter }
+	t.Cleanup(func() { newPutter = original })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := putItem(context.Background(), &dynamodb.PutItemInput{}); err != nil {
+				t.Errorf("putItem: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak := putter.peak.Load(); peak != 2 {
+		t.Errorf("peak concurrent writes = %d, want 2", peak)
+	}
+}
+
+func TestAcquireWriteRespectsContext(t *testing.T) {
+	limitWrites(t, "1")
+	release, err := acquireWrite(context.Background())
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := acquireWrite(ctx); err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want context.DeadlineExceeded while saturated", err)
+	}
+}
+
+func TestAcquireWriteUnbounded(t *testing.T) {
+	limitWrites(t, "")
+	for i := 0; i < 100; i++ {
+		if _, err := acquireWrite(context.Background()); err != nil {
+			t.Fatalf("acquire %d: %v", i, err)
+		}
+	}
+}
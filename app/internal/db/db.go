@@ -1,11 +1,16 @@
 package db
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"weather-lambda/internal/feature"
 	"weather-lambda/internal/log"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
@@ -15,31 +20,191 @@ type WeatherData struct {
 	City        string  `json:"City"`
 	Temperature float64 `json:"Temperature"`
 	Humidity    int     `json:"Humidity"`
+	Time        string  `json:"Time"`
+
+	// Provider names the upstream that produced the reading
+	Provider string `json:"Provider,omitempty"`
+
+	// DerivedFrom is set when the reading was not observed directly,
+	// e.g. "forecast" when it comes from the nearest forecast interval
+	DerivedFrom string `json:"DerivedFrom,omitempty"`
+
+	// Degraded is set when the upstream populated too few values; such
+	// readings are served but never cached or stored
+	Degraded bool `json:"Degraded,omitempty"`
+
+	Severe          bool     `json:"Severe"`
+	SeverityReasons []string `json:"SeverityReasons,omitempty"`
+
+	Location   *Location   `json:"Location,omitempty"`
+	AirQuality *AirQuality `json:"AirQuality,omitempty"`
+	MoonPhase  *MoonPhase  `json:"MoonPhase,omitempty"`
+	Trend      *Trend      `json:"Trend,omitempty"`
+	Delta      *Delta      `json:"Delta,omitempty"`
+	DailyRange *DailyRange `json:"DailyRange,omitempty"`
+	Conditions *Conditions `json:"Conditions,omitempty"`
+
+	// ExpiresAt, when set, is the Unix time after which the table's TTL
+	// removes the row; real readings leave it unset and are kept
+	ExpiresAt int64 `json:"ExpiresAt,omitempty"`
+}
+
+type Location struct {
+	Name string  `json:"Name"`
+	Lat  float64 `json:"Lat"`
+	Lon  float64 `json:"Lon"`
+}
+
+type AirQuality struct {
+	PM25     *float64 `json:"PM25,omitempty"`
+	PM10     *float64 `json:"PM10,omitempty"`
+	O3       *float64 `json:"O3,omitempty"`
+	NO2      *float64 `json:"NO2,omitempty"`
+	CO       *float64 `json:"CO,omitempty"`
+	SO2      *float64 `json:"SO2,omitempty"`
+	Category string   `json:"Category"`
+}
+
+type MoonPhase struct {
+	Phase *int   `json:"Phase,omitempty"`
+	Label string `json:"Label"`
+}
+
+// Trend compares a reading's temperature with the previous stored reading.
+type Trend struct {
+	Direction string  `json:"Direction"`
+	Delta     float64 `json:"Delta"`
+	Since     string  `json:"Since"`
 }
 
-func SaveWeatherData(data WeatherData) error {
+// Delta is the per-field change since the previous stored reading.
+type Delta struct {
+	Temperature float64 `json:"Temperature"`
+	Humidity    int     `json:"Humidity"`
+	Since       string  `json:"Since"`
+}
+
+// DailyRange is the temperature and humidity range over the readings stored
+// for a city on Date (UTC).
+type DailyRange struct {
+	Date           string  `json:"Date"`
+	MinTemperature float64 `json:"MinTemperature"`
+	MaxTemperature float64 `json:"MaxTemperature"`
+	MinHumidity    int     `json:"MinHumidity"`
+	MaxHumidity    int     `json:"MaxHumidity"`
+}
+
+// Conditions holds the parts of a reading used by the condensed summary.
+type Conditions struct {
+	FeelsLike     float64 `json:"FeelsLike"`
+	Condition     string  `json:"Condition"`
+	Precipitation bool    `json:"Precipitation"`
+	WindSpeed     float64 `json:"WindSpeed"`
+}
+
+// Feature flags are resolved once per container at cold start
+var features = feature.Current()
+
+func newClient(configs ...*aws.Config) *dynamodb.DynamoDB {
 	sess := session.Must(session.NewSession(&aws.Config{
 		Region: aws.String(os.Getenv("AWS_REGION")),
 	}))
-	svc := dynamodb.New(sess)
+	return dynamodb.New(sess, configs...)
+}
+
+// itemGetter is the part of the DynamoDB client the read path uses.
+type itemGetter interface {
+	GetItemWithContext(aws.Context, *dynamodb.GetItemInput, ...request.Option) (*dynamodb.GetItemOutput, error)
+}
+
+var newGetter = func() itemGetter {
+	return newClient()
+}
 
-	av, err := dynamodbattribute.MarshalMap(data)
+func SaveWeatherData(ctx context.Context, data WeatherData) error {
+	if disabled() {
+		return nil
+	}
+
+	if features.Enabled("skip-unchanged") && unchanged(ctx, data) {
+		log.InfoContext(ctx, fmt.Sprintf("Skipping unchanged weather data for city: %s", data.City))
+		return nil
+	}
+
+	var av map[string]*dynamodb.AttributeValue
+	var err error
+	if compressionEnabled() {
+		av, err = compressItem(data)
+	} else {
+		av, err = dynamodbattribute.MarshalMap(data)
+	}
 	if err != nil {
-		log.Error(fmt.Sprintf("Error marshalling weather data: %v", err))
+		log.ErrorContext(ctx, fmt.Sprintf("Error marshalling weather data: %v", err))
 		return err
 	}
 
 	input := &dynamodb.PutItemInput{
 		Item:      av,
-		TableName: aws.String(os.Getenv("DB_TABLE_NAME")),
+		TableName: aws.String(tableName(ctx)),
 	}
 
-	_, err = svc.PutItem(input)
-	if err != nil {
-		log.Error(fmt.Sprintf("Error saving weather data to DynamoDB: %v", err))
+	if err := putItem(ctx, input); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error saving weather data to DynamoDB: %v", err))
 		return err
 	}
 
-	log.Info(fmt.Sprintf("Successfully saved weather data for city: %s", data.City))
+	log.InfoContext(ctx, fmt.Sprintf("Successfully saved weather data for city: %s", data.City))
 	return nil
 }
+
+// unchanged reports whether the stored reading for data.City matches data in
+// everything but its observation time. Read errors count as changed.
+func unchanged(ctx context.Context, data WeatherData) bool {
+	stored, found, err := GetWeatherData(ctx, data.City)
+	if err != nil || !found {
+		return false
+	}
+
+	stored.Time, data.Time = "", ""
+	storedJSON, err := json.Marshal(stored)
+	if err != nil {
+		return false
+	}
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(storedJSON, dataJSON)
+}
+
+func GetWeatherData(ctx context.Context, city string) (WeatherData, bool, error) {
+	if disabled() {
+		return WeatherData{}, false, nil
+	}
+
+	svc := newGetter()
+
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"City": {S: aws.String(city)},
+		},
+		TableName: aws.String(tableName(ctx)),
+	}
+
+	result, err := svc.GetItemWithContext(ctx, input)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error reading weather data from DynamoDB: %v", err))
+		return WeatherData{}, false, err
+	}
+	if len(result.Item) == 0 {
+		return WeatherData{}, false, nil
+	}
+
+	data, err := unmarshalItem(result.Item)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error unmarshalling weather data: %v", err))
+		return WeatherData{}, false, err
+	}
+
+	return data, true, nil
+}
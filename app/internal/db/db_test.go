@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"weather-lambda/internal/feature"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// capturingPutter keeps the items written to it.
+type capturingPutter struct {
+	items []map[string]*dynamodb.AttributeValue
+}
+
+func (p *capturingPutter) PutItemWithContext(_ aws.Context, input *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	p.items = append(p.items, input.Item)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// useFeatures enables only the named features for the test.
+func useFeatures(t *testing.T, enabled ...string) {
+	t.Helper()
+	original := features
+	features = feature.Parse(strings.Join(enabled, ","))
+	t.Cleanup(func() { features = original })
+}
+
+func TestSaveWeatherDataPersistsProvider(t *testing.T) {
+	for _, enabled := range [][]string{nil, {"compress"}} {
+		t.Run(fmt.Sprintf("features=%v", enabled), func(t *testing.T) {
+			useFeatures(t, enabled...)
+			putter := &capturingPutter{}
+			original := newPutter
+			newPutter = func(...*aws.Config) itemPutter { return putter }
+			t.Cleanup(func() { newPutter = original })
+
+			for _, provider := range []string{"tomorrow.io", "other"} {
+				data := WeatherData{City: "Oslo", Temperature: -3, Time: "2024-01-01T00:00:00Z", Provider: provider}
+				if err := SaveWeatherData(context.Background(), data); err != nil {
+					t.Fatalf("SaveWeatherData: %v", err)
+				}
+			}
+
+			if len(putter.items) != 2 {
+				t.Fatalf("%d writes, want 2", len(putter.items))
+			}
+			for i, want := range []string{"tomorrow.io", "other"} {
+				stored, err := unmarshalItem(putter.items[i])
+				if err != nil {
+					t.Fatalf("unmarshalItem: %v", err)
+				}
+				if stored.Provider != want {
+					t.Errorf("write %d Provider = %q, want %q", i, stored.Provider, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSaveWeatherDataWritesExpiresAt(t *testing.T) {
+	for _, enabled := range [][]string{nil, {"compress"}} {
+		t.Run(fmt.Sprintf("features=%v", enabled), func(t *testing.T) {
+			useFeatures(t, enabled...)
+			putter := &capturingPutter{}
+			original := newPutter
+			newPutter = func(...*aws.Config) itemPutter { return putter }
+			t.Cleanup(func() { newPutter = original })
+
+			for _, expiresAt := range []int64{0, 1700000000} {
+				data := WeatherData{City: "Oslo", Time: "2024-01-01T00:00:00Z", ExpiresAt: expiresAt}
+				if err := SaveWeatherData(context.Background(), data); err != nil {
+					t.Fatalf("SaveWeatherData: %v", err)
+				}
+			}
+
+			if _, ok := putter.items[0]["ExpiresAt"]; ok {
+				t.Errorf("a reading without ExpiresAt was written with one")
+			}
+			if got := putter.items[1]["ExpiresAt"]; got == nil || aws.StringValue(got.N) != "1700000000" {
+				t.Errorf("ExpiresAt attribute = %v, want the number 1700000000", got)
+			}
+		})
+	}
+}
+
+// failingGetter fails every read.
+type failingGetter struct{}
+
+func (failingGetter) GetItemWithContext(aws.Context, *dynamodb.GetItemInput, ...request.Option) (*dynamodb.GetItemOutput, error) {
+	return nil, errors.New("read failed")
+}
+
+func TestSaveWeatherDataSkipsUnchanged(t *testing.T) {
+	stored := WeatherData{City: "Oslo", Temperature: -3, Humidity: 80, Time: "2024-01-01T00:00:00Z", Provider: "tomorrow.io"}
+	tests := []struct {
+		name      string
+		enabled   []string
+		next      WeatherData
+		failRead  bool
+		wantWrite bool
+	}{
+		{"only the time differs", []string{"skip-unchanged"}, withTime(stored, "2024-01-01T00:05:00Z"), false, false},
+		{"temperature changed", []string{"skip-unchanged"}, WeatherData{City: "Oslo", Temperature: -2, Humidity: 80, Time: "2024-01-01T00:05:00Z", Provider: "tomorrow.io"}, false, true},
+		{"extra field added", []string{"skip-unchanged"}, WeatherData{City: "Oslo", Temperature: -3, Humidity: 80, Time: "2024-01-01T00:05:00Z", Provider: "tomorrow.io", Severe: true}, false, true},
+		{"read failure still writes", []string{"skip-unchanged"}, withTime(stored, "2024-01-01T00:05:00Z"), true, true},
+		{"feature off writes identical readings", nil, withTime(stored, "2024-01-01T00:05:00Z"), false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PERSISTENCE", "")
+			useFeatures(t, tt.enabled...)
+			table := useFakeTable(t)
+			ctx := context.Background()
+			if err := SaveWeatherData(ctx, stored); err != nil {
+				t.Fatalf("SaveWeatherData: %v", err)
+			}
+			if tt.failRead {
+				newGetter = func() itemGetter { return failingGetter{} }
+			}
+
+			if err := SaveWeatherData(ctx, tt.next); err != nil {
+				t.Fatalf("SaveWeatherData: %v", err)
+			}
+			if wrote := table.puts == 2; wrote != tt.wantWrite {
+				t.Errorf("second reading written = %v, want %v", wrote, tt.wantWrite)
+			}
+		})
+	}
+}
+
+func withTime(data WeatherData, at string) WeatherData {
+	data.Time = at
+	return data
+}
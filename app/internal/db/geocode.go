@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"weather-lambda/internal/log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+const (
+	geocodeKeyPrefix  = "GEO#"
+	defaultGeocodeTTL = 30 * 24 * time.Hour
+)
+
+type Geocode struct {
+	City      string  `json:"City"`
+	Name      string  `json:"Name"`
+	Lat       float64 `json:"Lat"`
+	Lon       float64 `json:"Lon"`
+	ExpiresAt int64   `json:"ExpiresAt"`
+}
+
+// GetGeocode looks up the stored coordinates for a normalized city name.
+func GetGeocode(ctx context.Context, city string) (Geocode, bool, error) {
+	if disabled() {
+		return Geocode{}, false, nil
+	}
+
+	svc := newGetter()
+
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"City": {S: aws.String(geocodeKeyPrefix + city)},
+		},
+		TableName: aws.String(tableName(ctx)),
+	}
+
+	result, err := svc.GetItemWithContext(ctx, input)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error reading geocode from DynamoDB: %v", err))
+		return Geocode{}, false, err
+	}
+	if len(result.Item) == 0 {
+		return Geocode{}, false, nil
+	}
+
+	var geocode Geocode
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &geocode); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error unmarshalling geocode: %v", err))
+		return Geocode{}, false, err
+	}
+
+	// DynamoDB removes expired items lazily, so check the expiry ourselves
+	if geocode.ExpiresAt <= time.Now().Unix() {
+		return Geocode{}, false, nil
+	}
+
+	return geocode, true, nil
+}
+
+// SaveGeocode stores the coordinates for a normalized city name with a long TTL.
+func SaveGeocode(ctx context.Context, city string, geocode Geocode) error {
+	if disabled() {
+		return nil
+	}
+
+	svc := newPutter()
+
+	geocode.City = geocodeKeyPrefix + city
+	geocode.ExpiresAt = time.Now().Add(geocodeTTL()).Unix()
+
+	av, err := dynamodbattribute.MarshalMap(geocode)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error marshalling geocode: %v", err))
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(tableName(ctx)),
+	}
+
+	release, err := acquireWrite(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if _, err := svc.PutItemWithContext(ctx, input); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error saving geocode to DynamoDB: %v", err))
+		return err
+	}
+
+	log.InfoContext(ctx, fmt.Sprintf("Successfully saved geocode for city: %s", city))
+	return nil
+}
+
+func geocodeTTL() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("GEOCODE_TTL_HOURS"))
+	if err != nil || hours <= 0 {
+		return defaultGeocodeTTL
+	}
+	return time.Duration(hours) * time.Hour
+}
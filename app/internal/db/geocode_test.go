@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// fakeTable keeps items by City in memory, standing in for the table.
+type fakeTable struct {
+	items map[string]map[string]*dynamodb.AttributeValue
+	gets  int
+	puts  int
+}
+
+func (f *fakeTable) GetItemWithContext(_ aws.Context, input *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+	f.gets++
+	return &dynamodb.GetItemOutput{Item: f.items[aws.StringValue(input.Key["City"].S)]}, nil
+}
+
+func (f *fakeTable) PutItemWithContext(_ aws.Context, input *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	f.puts++
+	f.items[aws.StringValue(input.Item["City"].S)] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// useFakeTable routes every read and write to a fresh fakeTable.
+func useFakeTable(t *testing.T) *fakeTable {
+	t.Helper()
+	table := &fakeTable{items: map[string]map[string]*dynamodb.AttributeValue{}}
+	originalGetter, originalPutter := newGetter, newPutter
+	newGetter = func() itemGetter { return table }
+	newPutter = func(...*aws.Config) itemPutter { return table }
+	t.Cleanup(func() { newGetter, newPutter = originalGetter, originalPutter })
+	return table
+}
+
+func TestGeocodeRoundTrip(t *testing.T) {
+	table := useFakeTable(t)
+	ctx := context.Background()
+
+	if _, found, err := GetGeocode(ctx, "toronto"); found || err != nil {
+		t.Fatalf("GetGeocode before saving = found %v, err %v", found, err)
+	}
+
+	saved := Geocode{Name: "Toronto", Lat: 43.65, Lon: -79.38}
+	if err := SaveGeocode(ctx, "toronto", saved); err != nil {
+		t.Fatalf("SaveGeocode: %v", err)
+	}
+	if _, ok := table.items[geocodeKeyPrefix+"toronto"]; !ok {
+		t.Fatalf("geocode not stored under %storonto", geocodeKeyPrefix)
+	}
+
+	got, found, err := GetGeocode(ctx, "toronto")
+	if err != nil || !found {
+		t.Fatalf("GetGeocode = found %v, err %v", found, err)
+	}
+	if got.Name != saved.Name || got.Lat != saved.Lat || got.Lon != saved.Lon {
+		t.Errorf("GetGeocode = %+v, want %+v", got, saved)
+	}
+	if remaining := time.Until(time.Unix(got.ExpiresAt, 0)); remaining < defaultGeocodeTTL-time.Minute || remaining > defaultGeocodeTTL {
+		t.Errorf("geocode expires in %v, want about %v", remaining, defaultGeocodeTTL)
+	}
+}
+
+func TestGeocodeExpired(t *testing.T) {
+	table := useFakeTable(t)
+	ctx := context.Background()
+
+	if err := SaveGeocode(ctx, "oslo", Geocode{Lat: 59.9, Lon: 10.7}); err != nil {
+		t.Fatalf("SaveGeocode: %v", err)
+	}
+	// DynamoDB deletes expired items lazily, so one may still be read back
+	table.items[geocodeKeyPrefix+"oslo"]["ExpiresAt"].N = aws.String("1")
+	if _, found, _ := GetGeocode(ctx, "oslo"); found {
+		t.Errorf("an expired geocode was returned")
+	}
+}
@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"weather-lambda/internal/log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+const (
+	historyKeyPrefix = "USER#"
+
+	// MaxRecentCities bounds the stored history for each API key.
+	MaxRecentCities = 10
+)
+
+type History struct {
+	City   string   `json:"City"`
+	Cities []string `json:"Cities"`
+}
+
+// historyKey hashes the API key so the table never holds the raw credential.
+func historyKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return historyKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+// GetRecentCities returns the cities most recently queried with an API key,
+// newest first.
+func GetRecentCities(ctx context.Context, apiKey string) ([]string, error) {
+	if disabled() {
+		return []string{}, nil
+	}
+
+	svc := newGetter()
+
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			"City": {S: aws.String(historyKey(apiKey))},
+		},
+		TableName: aws.String(tableName(ctx)),
+	}
+
+	result, err := svc.GetItemWithContext(ctx, input)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error reading history from DynamoDB: %v", err))
+		return nil, err
+	}
+	if len(result.Item) == 0 {
+		return []string{}, nil
+	}
+
+	var history History
+	if err := dynamodbattribute.UnmarshalMap(result.Item, &history); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error unmarshalling history: %v", err))
+		return nil, err
+	}
+
+	return history.Cities, nil
+}
+
+// RecordCity moves city to the front of the history for an API key, dropping
+// any earlier entry for the same city and the oldest beyond MaxRecentCities.
+func RecordCity(ctx context.Context, apiKey string, city string) error {
+	if disabled() {
+		return nil
+	}
+
+	cities, err := GetRecentCities(ctx, apiKey)
+	if err != nil {
+		return err
+	}
+
+	history := History{City: historyKey(apiKey), Cities: pushRecent(cities, city)}
+
+	av, err := dynamodbattribute.MarshalMap(history)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error marshalling history: %v", err))
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(tableName(ctx)),
+	}
+
+	release, err := acquireWrite(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if _, err := newPutter().PutItemWithContext(ctx, input); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error saving history to DynamoDB: %v", err))
+		return err
+	}
+	return nil
+}
+
+func pushRecent(cities []string, city string) []string {
+	recent := []string{city}
+	for _, existing := range cities {
+		if len(recent) == MaxRecentCities {
+			break
+		}
+		if strings.EqualFold(existing, city) {
+			continue
+		}
+		recent = append(recent, existing)
+	}
+	return recent
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPushRecent(t *testing.T) {
+	full := make([]string, MaxRecentCities)
+	for i := range full {
+		full[i] = fmt.Sprintf("city-%d", i)
+	}
+
+	tests := []struct {
+		name   string
+		cities []string
+		city   string
+		want   []string
+	}{
+		{"empty", nil, "Oslo", []string{"Oslo"}},
+		{"newest first", []string{"Paris", "Rome"}, "Oslo", []string{"Oslo", "Paris", "Rome"}},
+		{"repeat moves to the front", []string{"Paris", "oslo", "Rome"}, "Oslo", []string{"Oslo", "Paris", "Rome"}},
+		{"oldest dropped when full", full, "Oslo", append([]string{"Oslo"}, full[:MaxRecentCities-1]...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pushRecent(tt.cities, tt.city); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pushRecent = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryKeyHidesTheAPIKey(t *testing.T) {
+	key := historyKey("secret-api-key")
+	if !strings.HasPrefix(key, historyKeyPrefix) || strings.Contains(key, "secret-api-key") {
+		t.Errorf("historyKey = %q, want a hashed USER# key", key)
+	}
+	if key != historyKey("secret-api-key") || key == historyKey("other-key") {
+		t.Errorf("historyKey is not a stable per-key hash")
+	}
+}
@@ -0,0 +1,14 @@
+package db
+
+import "os"
+
+// disabled reports PERSISTENCE=none, for cache-only deployments without a
+// table. Reads then find nothing and writes succeed without touching AWS.
+func disabled() bool {
+	return os.Getenv("PERSISTENCE") == "none"
+}
+
+// Enabled reports whether readings are persisted at all.
+func Enabled() bool {
+	return !disabled()
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestPersistenceNoneNeverCallsAWS(t *testing.T) {
+	t.Setenv("PERSISTENCE", "none")
+	t.Setenv("DB_TABLE_NAME", "weather-test")
+	t.Setenv("AWS_CA_BUNDLE", "")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	calls := 0
+	original := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(*http.Request) (*http.Response, error) {
+		calls++
+		return nil, errors.New("AWS called with PERSISTENCE=none")
+	})
+	t.Cleanup(func() { http.DefaultTransport = original })
+
+	ctx := context.Background()
+	if Enabled() {
+		t.Errorf("Enabled with PERSISTENCE=none")
+	}
+	if err := SaveWeatherData(ctx, WeatherData{City: "Oslo", Time: "2024-01-01T00:00:00Z"}); err != nil {
+		t.Errorf("SaveWeatherData: %v", err)
+	}
+	if _, found, err := GetWeatherData(ctx, "Oslo"); found || err != nil {
+		t.Errorf("GetWeatherData = found %v, err %v; want nothing", found, err)
+	}
+	if err := SaveGeocode(ctx, "oslo", Geocode{Lat: 59.9, Lon: 10.7}); err != nil {
+		t.Errorf("SaveGeocode: %v", err)
+	}
+	if _, found, err := GetGeocode(ctx, "oslo"); found || err != nil {
+		t.Errorf("GetGeocode = found %v, err %v; want nothing", found, err)
+	}
+	if err := RecordCity(ctx, "key", "Oslo"); err != nil {
+		t.Errorf("RecordCity: %v", err)
+	}
+	if cities, err := GetRecentCities(ctx, "key"); len(cities) != 0 || err != nil {
+		t.Errorf("GetRecentCities = %v, %v; want none", cities, err)
+	}
+	if calls != 0 {
+		t.Errorf("%d AWS requests with PERSISTENCE=none", calls)
+	}
+}
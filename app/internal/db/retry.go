@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+	"weather-lambda/internal/log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const deferredWriteTimeout = 5 * time.Second
+
+// itemPutter is the part of the DynamoDB client the write path uses.
+type itemPutter interface {
+	PutItemWithContext(aws.Context, *dynamodb.PutItemInput, ...request.Option) (*dynamodb.PutItemOutput, error)
+}
+
+var newPutter = func(configs ...*aws.Config) itemPutter {
+	return newClient(configs...)
+}
+
+// putItem writes with a fast retry policy by default: one immediate retry on a
+// retriable error, after which the error is returned. With
+// PERSIST_MODE=best-effort a write that failed twice is instead retried in the
+// background so the request is not held up. DB_WRITE_RETRY=backoff uses the
+// SDK's exponential backoff.
+func putItem(ctx context.Context, input *dynamodb.PutItemInput) error {
+	release, err := acquireWrite(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if os.Getenv("DB_WRITE_RETRY") == "backoff" {
+		_, err := newPutter().PutItemWithContext(ctx, input)
+		return err
+	}
+
+	svc := newPutter(aws.NewConfig().WithMaxRetries(0))
+
+	_, err = svc.PutItemWithContext(ctx, input)
+	if err == nil || !isRetriable(err) {
+		return err
+	}
+
+	log.InfoContext(ctx, fmt.Sprintf("Retrying DynamoDB write after retriable error: %v", err))
+	_, err = svc.PutItemWithContext(ctx, input)
+	if err == nil || !isRetriable(err) || os.Getenv("PERSIST_MODE") != "best-effort" {
+		return err
+	}
+
+	log.ErrorContext(ctx, fmt.Sprintf("DynamoDB write failed twice, deferring to a best-effort write: %v", err))
+	go func() {
+		deferredCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deferredWriteTimeout)
+		defer cancel()
+		release, err := acquireWrite(deferredCtx)
+		if err != nil {
+			log.ErrorContext(deferredCtx, fmt.Sprintf("Best-effort DynamoDB write gave up waiting for a slot: %v", err))
+			return
+		}
+		defer release()
+		if _, err := newPutter().PutItemWithContext(deferredCtx, input); err != nil {
+			log.ErrorContext(deferredCtx, fmt.Sprintf("Best-effort DynamoDB write failed: %v", err))
+		}
+	}()
+	return nil
+}
+
+func isRetriable(err error) bool {
+	return request.IsErrorRetryable(err) || request.IsErrorThrottle(err)
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+var errThrottled = awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "slow down", nil)
+
+// scriptedPutter fails with each error in turn, then succeeds.
+type scriptedPutter struct {
+	mu     sync.Mutex
+	errs   []error
+	calls  int
+	called chan struct{}
+}
+
+func (p *scriptedPutter) PutItemWithContext(aws.Context, *dynamodb.PutItemInput, ...request.Option) (*dynamodb.PutItemOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.called != nil {
+		p.called <- struct{}{}
+	}
+	if len(p.errs) > 0 {
+		err := p.errs[0]
+		p.errs = p.errs[1:]
+		return nil, err
+	}
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func usePutter(t *testing.T, p *scriptedPutter) {
+	t.Helper()
+	original := newPutter
+	newPutter = func(...*aws.Config) itemPutter { return p }
+	t.Cleanup(func() { newPutter = original })
+}
+
+func TestPutItemRetries(t *testing.T) {
+	errInvalid := awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "no", nil)
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantErr   error
+		wantCalls int
+	}{
+		{"first attempt succeeds", nil, nil, 1},
+		{"retriable then success", []error{errThrottled}, nil, 2},
+		{"not retriable", []error{errInvalid}, errInvalid, 1},
+		{"fails twice", []error{errThrottled, errThrottled}, errThrottled, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			putter := &scriptedPutter{errs: tt.errs}
+			usePutter(t, putter)
+
+			err := putItem(context.Background(), &dynamodb.PutItemInput{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if putter.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", putter.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPutItemBestEffortDefers(t *testing.T) {
+	t.Setenv("PERSIST_MODE", "best-effort")
+	putter := &scriptedPutter{errs: []error{errThrottled, errThrottled}, called: make(chan struct{}, 3)}
+	usePutter(t, putter)
+
+	if err := putItem(context.Background(), &dynamodb.PutItemInput{}); err != nil {
+		t.Fatalf("err = %v, want nil once the write is deferred", err)
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-putter.called:
+		case <-time.After(time.Second):
+			t.Fatalf("deferred write was not attempted; %d calls", i)
+		}
+	}
+}
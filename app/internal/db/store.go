@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"sync"
+)
+
+// Store persists weather readings keyed by city. The handler depends on this
+// interface so the DynamoDB backend can be swapped out.
+type Store interface {
+	Save(ctx context.Context, data WeatherData) error
+	Get(ctx context.Context, city string) (WeatherData, bool, error)
+}
+
+// DynamoStore is the default Store, backed by the DB_TABLE_NAME table.
+type DynamoStore struct{}
+
+func (DynamoStore) Save(ctx context.Context, data WeatherData) error {
+	return SaveWeatherData(ctx, data)
+}
+
+func (DynamoStore) Get(ctx context.Context, city string) (WeatherData, bool, error) {
+	return GetWeatherData(ctx, city)
+}
+
+// MemoryStore keeps readings in process memory, for tests and local runs.
+type MemoryStore struct {
+	mu   sync.Mutex
+	data map[string]WeatherData
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: make(map[string]WeatherData)}
+}
+
+func (m *MemoryStore) Save(ctx context.Context, data WeatherData) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[data.City] = data
+	return nil
+}
+
+func (m *MemoryStore) Get(ctx context.Context, city string) (WeatherData, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.data[city]
+	return data, ok, nil
+}
@@ -0,0 +1,20 @@
+package db
+
+import (
+	"context"
+	"os"
+)
+
+type tableNameKey struct{}
+
+// WithTableName overrides the DB_TABLE_NAME env var for calls made with ctx.
+func WithTableName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, tableNameKey{}, name)
+}
+
+func tableName(ctx context.Context) string {
+	if name, ok := ctx.Value(tableNameKey{}).(string); ok && name != "" {
+		return name
+	}
+	return os.Getenv("DB_TABLE_NAME")
+}
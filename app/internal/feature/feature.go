@@ -0,0 +1,73 @@
+package feature
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"weather-lambda/internal/log"
+)
+
+// known maps each supported feature name to the individual env var that
+// also enables it, so deployments using the older flags keep working.
+// Boolean toggles belong here rather than in ad hoc env checks.
+var known = map[string]string{
+	"metrics":           "METRICS_ENDPOINT",
+	"geocode-cache":     "GEOCODE_CACHE",
+	"history":           "TRACK_HISTORY",
+	"serve-stale":       "SERVE_STALE_WHEN_OPEN",
+	"viewer-geo":        "USE_VIEWER_GEO",
+	"forecast-fallback": "REALTIME_FORECAST_FALLBACK",
+	"validate-upstream": "VALIDATE_UPSTREAM",
+	"maintenance":       "MAINTENANCE_MODE",
+	"cache-aligned":     "CACHE_BUCKET_ALIGNED",
+	"skip-unchanged":    "DB_SKIP_UNCHANGED",
+	"compress":          "DB_COMPRESS",
+	"self-test":         "SELF_TEST",
+}
+
+type FeatureSet map[string]bool
+
+func Parse(value string) FeatureSet {
+	features := FeatureSet{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := known[name]; !ok {
+			log.Warn(fmt.Sprintf("Ignoring unknown feature: %s", name))
+			continue
+		}
+		features[name] = true
+	}
+	return features
+}
+
+func FromEnv() FeatureSet {
+	features := Parse(os.Getenv("FEATURES"))
+	for name, envVar := range known {
+		if envVar != "" && os.Getenv(envVar) == "true" {
+			features[name] = true
+		}
+	}
+	return features
+}
+
+var (
+	currentOnce sync.Once
+	current     FeatureSet
+)
+
+// Current returns the features enabled for this process. The environment is
+// read on the first call only, so unknown names are warned about once.
+func Current() FeatureSet {
+	currentOnce.Do(func() {
+		current = FromEnv()
+	})
+	return current
+}
+
+func (f FeatureSet) Enabled(name string) bool {
+	return f[name]
+}
@@ -0,0 +1,71 @@
+package feature
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{"metrics", []string{"metrics"}},
+		{" Metrics , HISTORY ", []string{"metrics", "history"}},
+		{"metrics,unknown", []string{"metrics"}},
+		{"serve-stale,viewer-geo,forecast-fallback,validate-upstream,maintenance",
+			[]string{"serve-stale", "viewer-geo", "forecast-fallback", "validate-upstream", "maintenance"}},
+		{"cache-aligned,skip-unchanged,compress,self-test",
+			[]string{"cache-aligned", "skip-unchanged", "compress", "self-test"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			features := Parse(tt.value)
+			if len(features) != len(tt.want) {
+				t.Fatalf("Parse(%q) = %v, want %v", tt.value, features, tt.want)
+			}
+			for _, name := range tt.want {
+				if !features.Enabled(name) {
+					t.Errorf("Parse(%q) did not enable %s", tt.value, name)
+				}
+			}
+		})
+	}
+}
+
+func TestFromEnvLegacyFlags(t *testing.T) {
+	for name, envVar := range known {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("FEATURES", "")
+			t.Setenv(envVar, "true")
+			if !FromEnv().Enabled(name) {
+				t.Errorf("%s=true did not enable %s", envVar, name)
+			}
+		})
+	}
+}
+
+func TestFromEnvCombinesSources(t *testing.T) {
+	t.Setenv("FEATURES", "metrics")
+	t.Setenv("TRACK_HISTORY", "true")
+	t.Setenv("GEOCODE_CACHE", "false")
+
+	features := FromEnv()
+	if !features.Enabled("metrics") || !features.Enabled("history") {
+		t.Errorf("FromEnv() = %v, want metrics and history", features)
+	}
+	if features.Enabled("geocode-cache") {
+		t.Errorf("GEOCODE_CACHE=false enabled geocode-cache")
+	}
+}
+
+func TestCurrentReadsEnvOnce(t *testing.T) {
+	t.Setenv("FEATURES", "metrics,compress")
+	first := Current()
+	if !first.Enabled("metrics") || !first.Enabled("compress") {
+		t.Fatalf("Current() = %v, want metrics and compress", first)
+	}
+
+	t.Setenv("FEATURES", "history")
+	if second := Current(); second.Enabled("history") || !second.Enabled("metrics") {
+		t.Errorf("Current() = %v after FEATURES changed, want the first reading", second)
+	}
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/log"
+	"weather-lambda/internal/weather"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type airport struct {
+	Name string
+	Lat  float64
+	Lon  float64
+}
+
+// Major airports keyed by IATA code. Add entries as clients need them.
+var airports = map[string]airport{
+	"AMS": {"Amsterdam Schiphol", 52.3105, 4.7683},
+	"ATL": {"Atlanta Hartsfield-Jackson", 33.6407, -84.4277},
+	"CDG": {"Paris Charles de Gaulle", 49.0097, 2.5479},
+	"DEN": {"Denver", 39.8561, -104.6737},
+	"DFW": {"Dallas/Fort Worth", 32.8998, -97.0403},
+	"DXB": {"Dubai", 25.2532, 55.3657},
+	"FRA": {"Frankfurt", 50.0379, 8.5622},
+	"GRU": {"São Paulo Guarulhos", -23.4356, -46.4731},
+	"HKG": {"Hong Kong", 22.3080, 113.9185},
+	"HND": {"Tokyo Haneda", 35.5494, 139.7798},
+	"JFK": {"New York John F. Kennedy", 40.6413, -73.7781},
+	"LAX": {"Los Angeles", 33.9416, -118.4085},
+	"LHR": {"London Heathrow", 51.4700, -0.4543},
+	"MEX": {"Mexico City", 19.4361, -99.0719},
+	"NRT": {"Tokyo Narita", 35.7720, 140.3929},
+	"ORD": {"Chicago O'Hare", 41.9742, -87.9073},
+	"SEA": {"Seattle-Tacoma", 47.4502, -122.3088},
+	"SFO": {"San Francisco", 37.6213, -122.3790},
+	"SIN": {"Singapore Changi", 1.3644, 103.9915},
+	"SYD": {"Sydney Kingsford Smith", -33.9399, 151.1753},
+	"YUL": {"Montréal-Trudeau", 45.4706, -73.7408},
+	"YVR": {"Vancouver", 49.1967, -123.1815},
+	"YYC": {"Calgary", 51.1215, -114.0076},
+	"YYZ": {"Toronto Pearson", 43.6777, -79.6248},
+}
+
+func isAirportRequest(request events.APIGatewayProxyRequest) bool {
+	return request.QueryStringParameters["airport"] != ""
+}
+
+// handleAirport fetches current conditions at an airport's coordinates.
+func handleAirport(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	code := strings.ToUpper(strings.TrimSpace(request.QueryStringParameters["airport"]))
+	location, ok := airports[code]
+	if !ok {
+		log.ErrorContext(ctx, fmt.Sprintf("Unknown airport code: %q", code))
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: unknown airport code %q", ErrValidation, code)
+	}
+
+	opts, err := parseRequestOptions(request.QueryStringParameters, defaultUnits(request))
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Invalid request options: %v", err))
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	opts.Region = acceptLanguageRegion(request.Headers)
+	if err := checkCoordinateOptions(opts); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Invalid request options: %v", err))
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	data, err := readingAt(ctx, "airport", code, location.Name, location.Lat, location.Lon, opts)
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	log.InfoContext(ctx, fmt.Sprintf("Returning data for airport: %s", code))
+	return buildWeatherResponse(data, opts)
+}
+
+// checkCoordinateOptions rejects options that compare against a stored
+// reading. Readings at fixed coordinates are never persisted, so there is no
+// earlier reading to compute a trend, delta or daily range from.
+func checkCoordinateOptions(opts RequestOptions) error {
+	switch {
+	case opts.IncludeTrend:
+		return fmt.Errorf("%w: includeTrend needs a city", ErrValidation)
+	case opts.IncludeDelta:
+		return fmt.Errorf("%w: includeDelta needs a city", ErrValidation)
+	case opts.IncludeDailyRange:
+		return fmt.Errorf("%w: includeDailyRange needs a city", ErrValidation)
+	}
+	return nil
+}
+
+// readingAt returns current conditions at fixed coordinates, cached under
+// namespace and key. Such readings are not persisted, so they never share a
+// table row with a city of the same name. name labels the location when the
+// upstream does not.
+func readingAt(ctx context.Context, namespace string, key string, name string, lat float64, lon float64, opts RequestOptions) (db.WeatherData, error) {
+	cacheKey := cache.NamespacedKey(extraFieldsNamespace(namespace, opts), key)
+
+	if cachedData, found := cache.GetCache(cacheKey); found {
+		if cachedWeather, ok := cachedData.(db.WeatherData); ok {
+			log.InfoContext(ctx, fmt.Sprintf("Returning cached data for %s: %s", namespace, key))
+			return cachedWeather, nil
+		}
+	}
+
+	coordinates := url.QueryEscape(fmt.Sprintf("%g,%g", lat, lon))
+	weatherResponse, err := weather.FetchWeather(ctx, weather.FetchOptions{Location: coordinates, Fields: extraFields(opts)})
+	recordUpstreamResult(err)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error fetching weather data: %v", err))
+		return db.WeatherData{}, fmt.Errorf("%w: %w", ErrUpstream, err)
+	}
+
+	values := weatherResponse.Data.Values
+	data := db.WeatherData{
+		City:        key,
+		Temperature: values.Temperature,
+		Humidity:    values.Humidity,
+		Time:        weatherResponse.Data.Time,
+		Provider:    weather.Provider,
+		Degraded:    weatherResponse.Degraded,
+		Location: &db.Location{
+			Name: weatherResponse.Location.Name,
+			Lat:  lat,
+			Lon:  lon,
+		},
+	}
+	if data.Location.Name == "" {
+		data.Location.Name = name
+	}
+	data.Severe, data.SeverityReasons = weather.Severity(values)
+	if opts.IncludeAirQuality {
+		data.AirQuality = airQuality(values)
+	}
+	if opts.IncludeMoonPhase {
+		data.MoonPhase = moonPhase(values)
+	}
+	if opts.Summary {
+		data.Conditions = conditions(values)
+	}
+
+	if !data.Degraded {
+		cache.SetCache(cacheKey, data)
+	}
+	return data, nil
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAirportLookup(t *testing.T) {
+	memory := setupHandler(t)
+	var requested string
+	stubUpstream(t, func(r *http.Request) (*http.Response, error) {
+		requested = r.URL.Query().Get("location")
+		return jsonResponse(200, `{"data":{"time":"2024-01-01T00:00:00Z","values":{"temperature":4,"humidity":80}},"location":{"lat":43.6777,"lon":-79.6248}}`), nil
+	})
+
+	tests := []struct {
+		name         string
+		code         string
+		wantStatus   int
+		wantLocation string
+		wantName     string
+	}{
+		{"known code", "YYZ", http.StatusOK, "43.6777,-79.6248", "Toronto Pearson"},
+		{"lower case", " sfo ", http.StatusOK, "37.6213,-122.379", "San Francisco"},
+		{"unknown code", "ZZZ", http.StatusBadRequest, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requested = ""
+			response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"airport": tt.code}, nil))
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", response.StatusCode, tt.wantStatus, response.Body)
+			}
+			if requested != tt.wantLocation {
+				t.Errorf("upstream location = %q, want %q", requested, tt.wantLocation)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if location := decodeReading(t, response).Location; location == nil || location.Name != tt.wantName {
+				t.Errorf("Location = %+v, want %q", location, tt.wantName)
+			}
+			if _, stored, _ := memory.Get(context.Background(), strings.ToUpper(strings.TrimSpace(tt.code))); stored {
+				t.Errorf("an airport reading was persisted")
+			}
+		})
+	}
+}
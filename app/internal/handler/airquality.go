@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/weather"
+)
+
+func airQuality(values weather.WeatherDataValues) *db.AirQuality {
+	aq := &db.AirQuality{
+		PM25:     values.ParticulateMatter25,
+		PM10:     values.ParticulateMatter10,
+		O3:       values.PollutantO3,
+		NO2:      values.PollutantNO2,
+		CO:       values.PollutantCO,
+		SO2:      values.PollutantSO2,
+		Category: "Unknown",
+	}
+	if aq.PM25 != nil {
+		aq.Category = weather.AQICategory(*aq.PM25)
+	}
+	return aq
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/log"
+)
+
+const (
+	alertTimeout    = 2 * time.Second
+	defaultAlertOn  = "severe,freezing"
+	freezingCelsius = 0.0
+)
+
+type AlertPayload struct {
+	City       string         `json:"City"`
+	Conditions []string       `json:"Conditions"`
+	Reading    db.WeatherData `json:"Reading"`
+}
+
+// alertConditions lists which ALERT_ON conditions a reading meets: "severe"
+// for any severe-weather threshold and "freezing" for temperatures at or
+// below 0 °C.
+func alertConditions(data db.WeatherData) []string {
+	value := os.Getenv("ALERT_ON")
+	if value == "" {
+		value = defaultAlertOn
+	}
+
+	var met []string
+	for _, condition := range strings.Split(value, ",") {
+		switch condition = strings.ToLower(strings.TrimSpace(condition)); condition {
+		case "severe":
+			if data.Severe {
+				met = append(met, condition)
+			}
+		case "freezing":
+			if data.Temperature <= freezingCelsius {
+				met = append(met, condition)
+			}
+		}
+	}
+	return met
+}
+
+// sendAlert posts a fresh reading to ALERT_WEBHOOK_URL when it meets any
+// configured condition. The post runs in the background with a short
+// timeout so it never delays the response, and failures are only logged.
+func sendAlert(ctx context.Context, data db.WeatherData) {
+	webhookURL := os.Getenv("ALERT_WEBHOOK_URL")
+	if webhookURL == "" {
+		return
+	}
+
+	conditions := alertConditions(data)
+	if len(conditions) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(AlertPayload{City: data.City, Conditions: conditions, Reading: data})
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error marshalling alert: %v", err))
+		return
+	}
+
+	go func() {
+		alertCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), alertTimeout)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(alertCtx, "POST", webhookURL, bytes.NewReader(body))
+		if err != nil {
+			log.ErrorContext(alertCtx, fmt.Sprintf("Error building alert request: %v", err))
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			log.ErrorContext(alertCtx, fmt.Sprintf("Error sending alert: %v", err))
+			return
+		}
+		resp.Body.Close()
+		log.InfoContext(alertCtx, fmt.Sprintf("Sent %s alert for city %s with status %d", strings.Join(conditions, ","), data.City, resp.StatusCode))
+	}()
+}
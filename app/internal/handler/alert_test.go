@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"weather-lambda/internal/db"
+)
+
+func TestAlertConditions(t *testing.T) {
+	tests := []struct {
+		name    string
+		alertOn string
+		data    db.WeatherData
+		want    []string
+	}{
+		{"mild", "", db.WeatherData{Temperature: 15}, nil},
+		{"freezing", "", db.WeatherData{Temperature: 0}, []string{"freezing"}},
+		{"severe and freezing", "", db.WeatherData{Temperature: -5, Severe: true}, []string{"severe", "freezing"}},
+		{"severe only configured", "severe", db.WeatherData{Temperature: -5}, nil},
+		{"unknown conditions ignored", " Freezing ,hail", db.WeatherData{Temperature: -1}, []string{"freezing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ALERT_ON", tt.alertOn)
+			if got := alertConditions(tt.data); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("alertConditions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendAlert(t *testing.T) {
+	tests := []struct {
+		name     string
+		webhook  string
+		data     db.WeatherData
+		wantPost bool
+	}{
+		{"no webhook", "", db.WeatherData{City: "Oslo", Temperature: -5}, false},
+		{"no condition met", "https://hooks.example/alert", db.WeatherData{City: "Oslo", Temperature: 15}, false},
+		{"freezing reading", "https://hooks.example/alert", db.WeatherData{City: "Oslo", Temperature: -5}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ALERT_WEBHOOK_URL", tt.webhook)
+			t.Setenv("ALERT_ON", "")
+			posts := make(chan *http.Request, 1)
+			bodies := make(chan []byte, 1)
+			stubUpstream(t, func(r *http.Request) (*http.Response, error) {
+				body, _ := io.ReadAll(r.Body)
+				posts <- r
+				bodies <- body
+				return jsonResponse(200, `{}`), nil
+			})
+
+			sendAlert(context.Background(), tt.data)
+
+			select {
+			case r := <-posts:
+				if !tt.wantPost {
+					t.Fatalf("unexpected alert POST to %s", r.URL)
+				}
+				if r.Method != http.MethodPost || r.URL.String() != tt.webhook || r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("request = %s %s (%s), want a JSON POST to the webhook", r.Method, r.URL, r.Header.Get("Content-Type"))
+				}
+				var payload AlertPayload
+				if err := json.Unmarshal(<-bodies, &payload); err != nil {
+					t.Fatalf("decode payload: %v", err)
+				}
+				if payload.City != "Oslo" || !reflect.DeepEqual(payload.Conditions, []string{"freezing"}) {
+					t.Errorf("payload = %+v, want a freezing alert for Oslo", payload)
+				}
+			case <-time.After(200 * time.Millisecond):
+				if tt.wantPost {
+					t.Errorf("no alert was posted")
+				}
+			}
+		})
+	}
+}
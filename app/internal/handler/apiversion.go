@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Response schema versions. Version 1 is the flat object clients have always
+// received; version 2 wraps it in an envelope with request metadata.
+const (
+	apiVersionFlat      = 1
+	apiVersionEnveloped = 2
+	latestAPIVersion    = apiVersionEnveloped
+)
+
+type Envelope struct {
+	Data json.RawMessage `json:"data"`
+	Meta EnvelopeMeta    `json:"meta"`
+}
+
+type EnvelopeMeta struct {
+	APIVersion int    `json:"apiVersion"`
+	RequestID  string `json:"requestId"`
+}
+
+// requestedAPIVersion reads the apiVersion parameter, or a version parameter
+// on the Accept header such as "application/json; version=2", defaulting to
+// version 1. Unknown versions are rejected unless API_VERSION_FALLBACK=latest.
+func requestedAPIVersion(request events.APIGatewayProxyRequest) (int, error) {
+	value := request.QueryStringParameters["apiVersion"]
+	if value == "" {
+		value = acceptVersion(headerValue(request.Headers, "Accept"))
+	}
+	if value == "" {
+		return apiVersionFlat, nil
+	}
+
+	version, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(value), "v"))
+	if err == nil && version >= apiVersionFlat && version <= latestAPIVersion {
+		return version, nil
+	}
+	if os.Getenv("API_VERSION_FALLBACK") == "latest" {
+		return latestAPIVersion, nil
+	}
+	return 0, fmt.Errorf("%w: unsupported apiVersion %q", ErrValidation, value)
+}
+
+func acceptVersion(accept string) string {
+	for _, param := range strings.Split(accept, ";") {
+		if name, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.EqualFold(name, "version") {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// envelope wraps a successful API body in the version 2 envelope. Bodies
+// with their own content type, such as the OpenAPI document, raw upstream
+// data or GeoJSON, keep their standard shapes.
+func envelope(response events.APIGatewayProxyResponse, id string) (events.APIGatewayProxyResponse, error) {
+	if response.Body == "" || response.Headers["Content-Type"] != "" {
+		return response, nil
+	}
+
+	body, err := json.Marshal(Envelope{
+		Data: json.RawMessage(response.Body),
+		Meta: EnvelopeMeta{APIVersion: apiVersionEnveloped, RequestID: id},
+	})
+	if err != nil {
+		return response, err
+	}
+	response.Body = string(body)
+	return response, nil
+}
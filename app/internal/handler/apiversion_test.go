@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"weather-lambda/internal/db"
+)
+
+func TestRequestedAPIVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   map[string]string
+		accept   string
+		fallback string
+		want     int
+		wantErr  bool
+	}{
+		{name: "default", want: apiVersionFlat},
+		{name: "parameter", params: map[string]string{"apiVersion": "2"}, want: apiVersionEnveloped},
+		{name: "v prefix", params: map[string]string{"apiVersion": "V1"}, want: apiVersionFlat},
+		{name: "Accept header", accept: "application/json; version=2", want: apiVersionEnveloped},
+		{name: "parameter wins over Accept", params: map[string]string{"apiVersion": "1"}, accept: "application/json; version=2", want: apiVersionFlat},
+		{name: "unknown version", params: map[string]string{"apiVersion": "3"}, wantErr: true},
+		{name: "not a number", params: map[string]string{"apiVersion": "latest"}, wantErr: true},
+		{name: "unknown version falls back", params: map[string]string{"apiVersion": "3"}, fallback: "latest", want: latestAPIVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("API_VERSION_FALLBACK", tt.fallback)
+			request := events.APIGatewayProxyRequest{
+				QueryStringParameters: tt.params,
+				Headers:               map[string]string{"Accept": tt.accept},
+			}
+			got, err := requestedAPIVersion(request)
+			if tt.wantErr {
+				if !errors.Is(err, ErrValidation) {
+					t.Errorf("err = %v, want ErrValidation", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("requestedAPIVersion = %d, %v; want %d", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIVersionShapes(t *testing.T) {
+	setupHandler(t)
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		return jsonResponse(200, realtimeBody(21.5, 50)), nil
+	})
+	city := uniqueCity(t)
+	headers := map[string]string{"X-Request-Id": "req-1"}
+
+	t.Run("v1 is flat", func(t *testing.T) {
+		response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city, "apiVersion": "1"}, headers))
+		if response.StatusCode != 200 {
+			t.Fatalf("status = %d; body %s", response.StatusCode, response.Body)
+		}
+		if got := decodeReading(t, response).Temperature; got != 21.5 {
+			t.Errorf("temperature = %v, want 21.5", got)
+		}
+	})
+
+	t.Run("v2 is enveloped", func(t *testing.T) {
+		response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city, "apiVersion": "2"}, headers))
+		if response.StatusCode != 200 {
+			t.Fatalf("status = %d; body %s", response.StatusCode, response.Body)
+		}
+		var body Envelope
+		if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+			t.Fatalf("decode envelope %q: %v", response.Body, err)
+		}
+		if body.Meta.APIVersion != apiVersionEnveloped || body.Meta.RequestID != "req-1" {
+			t.Errorf("meta = %+v, want version 2 and request ID req-1", body.Meta)
+		}
+		var reading db.WeatherData
+		if err := json.Unmarshal(body.Data, &reading); err != nil || reading.Temperature != 21.5 {
+			t.Errorf("data = %s, want the flat reading", body.Data)
+		}
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city, "apiVersion": "9"}, headers))
+		if response.StatusCode != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", response.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const apiKeyHeader = "X-Api-Key"
+
+// isAuthorized reports whether the request carries the ADMIN_API_KEY used to
+// protect debugging features. Nothing is authorized when the key is unset.
+func isAuthorized(request events.APIGatewayProxyRequest) bool {
+	adminKey := os.Getenv("ADMIN_API_KEY")
+	if adminKey == "" {
+		return false
+	}
+	provided := headerValue(request.Headers, apiKeyHeader)
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(adminKey)) == 1
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"weather-lambda/internal/log"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	defaultBBoxGrid      = 3
+	defaultBBoxMaxPoints = 25
+)
+
+type gridPoint struct {
+	Lat float64
+	Lon float64
+}
+
+// BBoxPoint is the reading at one grid point, or the error that stopped it.
+type BBoxPoint struct {
+	Lat     float64   `json:"Lat"`
+	Lon     float64   `json:"Lon"`
+	Weather *Response `json:"Weather,omitempty"`
+	Error   string    `json:"Error,omitempty"`
+}
+
+func isBBoxRequest(request events.APIGatewayProxyRequest) bool {
+	return request.QueryStringParameters["bbox"] != ""
+}
+
+// handleBBox fetches current conditions at the centre of each cell of a
+// grid laid over bbox=minLat,minLon,maxLat,maxLon. grid sets the cells per
+// side; the total is capped by BBOX_MAX_POINTS to bound upstream quota use.
+// Points are fetched concurrently and a failed point is reported in place,
+// so the request only fails when every point does.
+func handleBBox(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	params := request.QueryStringParameters
+	points, err := bboxGrid(params["bbox"], params["grid"], envLimit("BBOX_MAX_POINTS", defaultBBoxMaxPoints))
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Invalid bounding box: %v", err))
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	opts, err := parseRequestOptions(params, defaultUnits(request))
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Invalid request options: %v", err))
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	opts.Region = acceptLanguageRegion(request.Headers)
+	if err := checkCoordinateOptions(opts); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Invalid request options: %v", err))
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	results := make([]BBoxPoint, len(points))
+	errs := make([]error, len(points))
+	var wg sync.WaitGroup
+	for i, point := range points {
+		wg.Add(1)
+		go func(i int, point gridPoint) {
+			defer wg.Done()
+			results[i] = BBoxPoint{Lat: point.Lat, Lon: point.Lon}
+			key := strconv.FormatFloat(point.Lat, 'f', 4, 64) + "," + strconv.FormatFloat(point.Lon, 'f', 4, 64)
+			data, err := readingAt(ctx, "bbox", key, key, point.Lat, point.Lon, opts)
+			if err == nil {
+				response := &Response{Data: data}
+				err = applyTransformers(response, opts)
+				results[i].Weather = response
+			}
+			if err != nil {
+				errs[i] = err
+				results[i].Weather = nil
+				results[i].Error = err.Error()
+			}
+		}(i, point)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err == nil {
+			log.InfoContext(ctx, fmt.Sprintf("Returning %d grid points for bbox: %s", len(points), params["bbox"]))
+			return buildResponse(results)
+		}
+	}
+	return events.APIGatewayProxyResponse{}, errs[0]
+}
+
+// bboxGrid parses the bounding box and returns the centre of each cell of a
+// grid-by-grid layout over it, south-west first.
+func bboxGrid(bbox string, grid string, maxPoints int) ([]gridPoint, error) {
+	parts := strings.Split(bbox, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("%w: bbox must be minLat,minLon,maxLat,maxLon", ErrValidation)
+	}
+	var bounds [4]float64
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bbox must be minLat,minLon,maxLat,maxLon", ErrValidation)
+		}
+		bounds[i] = value
+	}
+	minLat, minLon, maxLat, maxLon := bounds[0], bounds[1], bounds[2], bounds[3]
+	if minLat < -90 || maxLat > 90 || minLon < -180 || maxLon > 180 {
+		return nil, fmt.Errorf("%w: bbox is outside valid coordinates", ErrValidation)
+	}
+	if minLat >= maxLat || minLon >= maxLon {
+		return nil, fmt.Errorf("%w: bbox minimums must be below its maximums", ErrValidation)
+	}
+
+	size := defaultBBoxGrid
+	if grid != "" {
+		parsed, err := strconv.Atoi(grid)
+		if err != nil || parsed < 1 {
+			return nil, fmt.Errorf("%w: grid must be a positive integer", ErrValidation)
+		}
+		size = parsed
+	}
+	if size*size > maxPoints {
+		return nil, fmt.Errorf("%w: grid of %dx%d exceeds %d points", ErrValidation, size, size, maxPoints)
+	}
+
+	latStep := (maxLat - minLat) / float64(size)
+	lonStep := (maxLon - minLon) / float64(size)
+	points := make([]gridPoint, 0, size*size)
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			points = append(points, gridPoint{
+				Lat: minLat + latStep*(float64(row)+0.5),
+				Lon: minLon + lonStep*(float64(col)+0.5),
+			})
+		}
+	}
+	return points, nil
+}
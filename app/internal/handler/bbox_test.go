@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestBBoxGrid(t *testing.T) {
+	tests := []struct {
+		name      string
+		bbox      string
+		grid      string
+		maxPoints int
+		want      []gridPoint
+		wantErr   bool
+	}{
+		{
+			name:      "single cell",
+			bbox:      "0,0,2,4",
+			grid:      "1",
+			maxPoints: 25,
+			want:      []gridPoint{{1, 2}},
+		},
+		{
+			name:      "two by two, south-west first",
+			bbox:      "0, 0, 2, 4",
+			grid:      "2",
+			maxPoints: 25,
+			want:      []gridPoint{{0.5, 1}, {0.5, 3}, {1.5, 1}, {1.5, 3}},
+		},
+		{name: "default grid", bbox: "0,0,3,3", maxPoints: 25, want: []gridPoint{
+			{0.5, 0.5}, {0.5, 1.5}, {0.5, 2.5},
+			{1.5, 0.5}, {1.5, 1.5}, {1.5, 2.5},
+			{2.5, 0.5}, {2.5, 1.5}, {2.5, 2.5},
+		}},
+		{name: "grid at the cap", bbox: "0,0,1,1", grid: "5", maxPoints: 25},
+		{name: "grid over the cap", bbox: "0,0,1,1", grid: "6", maxPoints: 25, wantErr: true},
+		{name: "default grid over a lower cap", bbox: "0,0,1,1", maxPoints: 4, wantErr: true},
+		{name: "too few values", bbox: "0,0,1", maxPoints: 25, wantErr: true},
+		{name: "not a number", bbox: "0,0,1,east", maxPoints: 25, wantErr: true},
+		{name: "out of range", bbox: "-91,0,1,1", maxPoints: 25, wantErr: true},
+		{name: "inverted", bbox: "1,1,0,0", maxPoints: 25, wantErr: true},
+		{name: "zero grid", bbox: "0,0,1,1", grid: "0", maxPoints: 25, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := bboxGrid(tt.bbox, tt.grid, tt.maxPoints)
+			if tt.wantErr {
+				if !errors.Is(err, ErrValidation) {
+					t.Errorf("err = %v, want ErrValidation", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("bboxGrid: %v", err)
+			}
+			if tt.want != nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("points = %v, want %v", got, tt.want)
+			}
+			if tt.want == nil && len(got) != tt.maxPoints {
+				t.Errorf("got %d points, want %d", len(got), tt.maxPoints)
+			}
+		})
+	}
+}
+
+func TestCoordinateRoutesRejectStoredComparisons(t *testing.T) {
+	setupHandler(t)
+	calls := 0
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponse(200, realtimeBody(20, 50)), nil
+	})
+
+	routes := map[string]map[string]string{
+		"airport": {"airport": "LHR"},
+		"bbox":    {"bbox": "0,0,1,1", "grid": "1"},
+	}
+	for route, base := range routes {
+		for _, param := range []string{"includeTrend", "includeDelta", "includeDailyRange"} {
+			t.Run(route+" "+param, func(t *testing.T) {
+				params := map[string]string{param: "true"}
+				for key, value := range base {
+					params[key] = value
+				}
+				before := calls
+				response, _ := HandleRequest(context.Background(), weatherRequest(params, nil))
+				if response.StatusCode != http.StatusBadRequest {
+					t.Errorf("status = %d, want 400", response.StatusCode)
+				}
+				if calls != before {
+					t.Errorf("a rejected request reached the upstream")
+				}
+			})
+		}
+		t.Run(route+" plain", func(t *testing.T) {
+			response, _ := HandleRequest(context.Background(), weatherRequest(base, nil))
+			if response.StatusCode != http.StatusOK {
+				t.Errorf("status = %d, want 200; body %s", response.StatusCode, response.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// cleanCity strips invisible formatting characters that would fragment cache
+// and table keys or enable homograph tricks. Invalid UTF-8 is rejected unless
+// CITY_INVALID_UTF8=sanitize, in which case the bad bytes are dropped.
+func cleanCity(city string) (string, error) {
+	if !utf8.ValidString(city) {
+		if os.Getenv("CITY_INVALID_UTF8") != "sanitize" {
+			return "", fmt.Errorf("%w: city is not valid UTF-8", ErrValidation)
+		}
+		city = strings.ToValidUTF8(city, "")
+	}
+
+	return strings.Map(func(r rune) rune {
+		if isInvisible(r) {
+			return -1
+		}
+		return r
+	}, city), nil
+}
+
+// isInvisible reports zero-width and bidirectional control characters.
+func isInvisible(r rune) bool {
+	switch {
+	case r >= '\u200B' && r <= '\u200F':
+		return true
+	case r >= '\u202A' && r <= '\u202E':
+		return true
+	case r >= '\u2060' && r <= '\u2069':
+		return true
+	case r == '\uFEFF':
+		return true
+	}
+	return false
+}
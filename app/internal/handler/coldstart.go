@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"sync/atomic"
+
+	"weather-lambda/internal/metrics"
+)
+
+const coldStartHeader = "X-Cold-Start"
+
+// warm is set by the first invocation in a container, including warm-up pings.
+var warm atomic.Bool
+
+// coldStart reports whether this is the container's first invocation.
+func coldStart() bool {
+	if warm.Swap(true) {
+		return false
+	}
+	metrics.ColdStarts.Inc()
+	return true
+}
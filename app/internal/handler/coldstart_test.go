@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+	"testing"
+)
+
+func TestColdStartHeader(t *testing.T) {
+	setupHandler(t)
+	original := warm.Load()
+	warm.Store(false)
+	t.Cleanup(func() { warm.Store(original) })
+
+	request := weatherRequest(map[string]string{"action": "schema"}, nil)
+	for i, want := range []string{"true", "false", "false"} {
+		response, err := HandleRequest(context.Background(), request)
+		if err != nil {
+			t.Fatalf("HandleRequest: %v", err)
+		}
+		if got := response.Headers[coldStartHeader]; got != want {
+			t.Errorf("invocation %d: %s = %q, want %q", i+1, coldStartHeader, got, want)
+		}
+	}
+}
+
+func TestWarmupEndsColdStart(t *testing.T) {
+	setupHandler(t)
+	original := warm.Load()
+	warm.Store(false)
+	t.Cleanup(func() { warm.Store(original) })
+
+	HandleRequest(context.Background(), Event{Warmup: true})
+	response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"action": "schema"}, nil))
+	if got := response.Headers[coldStartHeader]; got != "false" {
+		t.Errorf("%s after a warm-up ping = %q, want false", coldStartHeader, got)
+	}
+}
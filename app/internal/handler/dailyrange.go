@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"weather-lambda/internal/db"
+)
+
+// dailyRange extends the range carried by the previous reading with the
+// current one. It returns nil when the previous reading is not from the same
+// UTC day, as there is no earlier reading today to form a range with.
+func dailyRange(current db.WeatherData, previous db.WeatherData) *db.DailyRange {
+	day := readingDay(current.Time)
+	if day == "" || readingDay(previous.Time) != day {
+		return nil
+	}
+
+	daily := db.DailyRange{
+		Date:           day,
+		MinTemperature: previous.Temperature,
+		MaxTemperature: previous.Temperature,
+		MinHumidity:    previous.Humidity,
+		MaxHumidity:    previous.Humidity,
+	}
+	if previous.DailyRange != nil && previous.DailyRange.Date == day {
+		daily.MinTemperature = math.Min(daily.MinTemperature, previous.DailyRange.MinTemperature)
+		daily.MaxTemperature = math.Max(daily.MaxTemperature, previous.DailyRange.MaxTemperature)
+		daily.MinHumidity = min(daily.MinHumidity, previous.DailyRange.MinHumidity)
+		daily.MaxHumidity = max(daily.MaxHumidity, previous.DailyRange.MaxHumidity)
+	}
+	daily.MinTemperature = math.Min(daily.MinTemperature, current.Temperature)
+	daily.MaxTemperature = math.Max(daily.MaxTemperature, current.Temperature)
+	daily.MinHumidity = min(daily.MinHumidity, current.Humidity)
+	daily.MaxHumidity = max(daily.MaxHumidity, current.Humidity)
+	return &daily
+}
+
+// withDailyRange returns the reading to store with today's range carried
+// forward, whether or not the request asked for it. The range lives on the
+// city's single row, so a write without it would drop the extremes seen
+// earlier in the day. It covers the readings that reached the store.
+func withDailyRange(ctx context.Context, data db.WeatherData) db.WeatherData {
+	if data.DailyRange != nil {
+		return data
+	}
+	if previous, found := previousReading(ctx, data); found {
+		data.DailyRange = dailyRange(data, previous)
+	}
+	return data
+}
+
+// readingDay returns the UTC date of a reading time, or "" if it is invalid.
+func readingDay(value string) string {
+	observedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return ""
+	}
+	return observedAt.UTC().Format(time.DateOnly)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"weather-lambda/internal/db"
+)
+
+func TestDailyRange(t *testing.T) {
+	reading := func(at string, temperature float64, humidity int) db.WeatherData {
+		return db.WeatherData{Time: at, Temperature: temperature, Humidity: humidity}
+	}
+	tests := []struct {
+		name     string
+		current  db.WeatherData
+		previous db.WeatherData
+		want     *db.DailyRange
+	}{
+		{
+			name:     "computed from the previous reading",
+			current:  reading("2024-03-01T12:00:00Z", 14, 40),
+			previous: reading("2024-03-01T09:00:00Z", 8, 70),
+			want:     &db.DailyRange{Date: "2024-03-01", MinTemperature: 8, MaxTemperature: 14, MinHumidity: 40, MaxHumidity: 70},
+		},
+		{
+			name:    "extends the previous range",
+			current: reading("2024-03-01T15:00:00Z", 12, 50),
+			previous: db.WeatherData{Time: "2024-03-01T12:00:00Z", Temperature: 14, Humidity: 40,
+				DailyRange: &db.DailyRange{Date: "2024-03-01", MinTemperature: 3, MaxTemperature: 14, MinHumidity: 40, MaxHumidity: 90}},
+			want: &db.DailyRange{Date: "2024-03-01", MinTemperature: 3, MaxTemperature: 14, MinHumidity: 40, MaxHumidity: 90},
+		},
+		{
+			name:    "ignores yesterday's range",
+			current: reading("2024-03-02T01:00:00Z", 5, 80),
+			previous: db.WeatherData{Time: "2024-03-02T00:30:00Z", Temperature: 6, Humidity: 75,
+				DailyRange: &db.DailyRange{Date: "2024-03-01", MinTemperature: -10, MaxTemperature: 20, MinHumidity: 10, MaxHumidity: 99}},
+			want: &db.DailyRange{Date: "2024-03-02", MinTemperature: 5, MaxTemperature: 6, MinHumidity: 75, MaxHumidity: 80},
+		},
+		{
+			name:     "no history today",
+			current:  reading("2024-03-02T01:00:00Z", 5, 80),
+			previous: reading("2024-03-01T23:00:00Z", 6, 75),
+		},
+		{
+			name:     "invalid time",
+			current:  reading("soon", 5, 80),
+			previous: reading("2024-03-01T23:00:00Z", 6, 75),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dailyRange(tt.current, tt.previous)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("dailyRange = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDailyRangeSurvivesWritesWithoutIt stores a reading without
+// includeDailyRange between two others; the extreme it saw must be kept.
+// Each request uses different options so none is served from the cache.
+func TestDailyRangeSurvivesWritesWithoutIt(t *testing.T) {
+	setupHandler(t)
+	city := uniqueCity(t)
+	readings := []struct {
+		at          string
+		temperature float64
+		param       string
+	}{
+		{"2024-03-01T08:00:00Z", 5, "includeDailyRange"},
+		{"2024-03-01T12:00:00Z", 30, "includeMoonPhase"},
+		{"2024-03-01T16:00:00Z", 10, "includeDailyRange"},
+	}
+
+	for i, reading := range readings {
+		stubUpstream(t, func(*http.Request) (*http.Response, error) {
+			return jsonResponse(200, fmt.Sprintf(`{"data":{"time":%q,"values":{"temperature":%v,"humidity":50}},"location":{"name":"Test"}}`,
+				reading.at, reading.temperature)), nil
+		})
+		params := map[string]string{"city": city, reading.param: "true"}
+		if i == len(readings)-1 {
+			params["includeAirQuality"] = "true"
+		}
+		response, _ := HandleRequest(context.Background(), weatherRequest(params, nil))
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("request %d status = %d; body %s", i, response.StatusCode, response.Body)
+		}
+
+		daily := decodeReading(t, response).DailyRange
+		switch i {
+		case 0:
+			if daily != nil {
+				t.Errorf("with no earlier reading DailyRange = %+v, want none", daily)
+			}
+		case len(readings) - 1:
+			if daily == nil || daily.MinTemperature != 5 || daily.MaxTemperature != 30 {
+				t.Errorf("DailyRange = %+v, want 5..30 including the reading fetched without includeDailyRange", daily)
+			}
+		}
+	}
+}
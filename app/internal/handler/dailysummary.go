@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"math"
+	"time"
+
+	"weather-lambda/internal/weather"
+)
+
+// DailySummary condenses a day of forecast intervals into what calendar and
+// agenda views show. Values the upstream did not return are omitted.
+type DailySummary struct {
+	Date                     string   `json:"Date"`
+	High                     *float64 `json:"High,omitempty"`
+	Low                      *float64 `json:"Low,omitempty"`
+	Condition                string   `json:"Condition"`
+	PrecipitationProbability *float64 `json:"PrecipitationProbability,omitempty"`
+}
+
+type DailySummaryResponse struct {
+	City string         `json:"City"`
+	Days []DailySummary `json:"Days"`
+}
+
+func isDailySummaryRequest(params map[string]string) bool {
+	return params["format"] == "daily-summary"
+}
+
+// Summaries prefer the daily timeline, then the finer ones grouped by day
+var summaryTimesteps = []string{"1d", "1h", "1m"}
+
+// dailySummary summarizes the coarsest timeline in the response by UTC date.
+// The high and low are the extremes of temperatureMax/temperatureMin, or of
+// temperature for hourly and minutely intervals; the precipitation
+// probability is the day's highest; the condition is its most frequent
+// weather code.
+func dailySummary(response ForecastResponse) DailySummaryResponse {
+	summary := DailySummaryResponse{City: response.City, Days: []DailySummary{}}
+
+	var intervals []weather.ForecastInterval
+	for _, timestep := range summaryTimesteps {
+		if timeline := response.Timelines[timestep]; len(timeline) > 0 {
+			intervals = timeline
+			break
+		}
+	}
+
+	var dates []string
+	days := map[string]*DailySummary{}
+	codeCounts := map[string]map[int]int{}
+	for _, interval := range intervals {
+		observedAt, err := time.Parse(time.RFC3339, interval.Time)
+		if err != nil {
+			continue
+		}
+		date := observedAt.UTC().Format(time.DateOnly)
+		day, ok := days[date]
+		if !ok {
+			day = &DailySummary{Date: date}
+			days[date] = day
+			codeCounts[date] = map[int]int{}
+			dates = append(dates, date)
+		}
+
+		day.High = extreme(day.High, firstValue(interval.Values, "temperatureMax", "temperature"), math.Max)
+		day.Low = extreme(day.Low, firstValue(interval.Values, "temperatureMin", "temperature"), math.Min)
+		day.PrecipitationProbability = extreme(day.PrecipitationProbability,
+			firstValue(interval.Values, "precipitationProbabilityMax", "precipitationProbability", "precipitationProbabilityAvg"), math.Max)
+		if code := firstValue(interval.Values, "weatherCodeMax", "weatherCode"); code != nil {
+			codeCounts[date][int(*code)]++
+		}
+	}
+
+	// Intervals are chronological, so days keep the order they first appear in
+	for _, date := range dates {
+		day := days[date]
+		day.Condition = weather.WeatherCodeLabel(mostFrequent(codeCounts[date]))
+		summary.Days = append(summary.Days, *day)
+	}
+	return summary
+}
+
+func firstValue(values map[string]*float64, names ...string) *float64 {
+	for _, name := range names {
+		if value := values[name]; value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+func extreme(current *float64, value *float64, pick func(float64, float64) float64) *float64 {
+	if value == nil {
+		return current
+	}
+	if current == nil {
+		v := *value
+		return &v
+	}
+	v := pick(*current, *value)
+	return &v
+}
+
+// mostFrequent returns the code seen most often, the lowest on a tie, or 0.
+func mostFrequent(counts map[int]int) int {
+	best, bestCount := 0, 0
+	for code, count := range counts {
+		if count > bestCount || (count == bestCount && code < best) {
+			best, bestCount = code, count
+		}
+	}
+	return best
+}
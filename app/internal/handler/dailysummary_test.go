@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"weather-lambda/internal/weather"
+)
+
+func TestDailySummary(t *testing.T) {
+	hourly := []weather.ForecastInterval{
+		{Time: "2024-03-01T06:00:00Z", Values: map[string]*float64{"temperature": ptr(2.0), "precipitationProbability": ptr(10.0), "weatherCode": ptr(1000.0)}},
+		{Time: "2024-03-01T12:00:00Z", Values: map[string]*float64{"temperature": ptr(9.5), "precipitationProbability": ptr(60.0), "weatherCode": ptr(4001.0)}},
+		{Time: "2024-03-01T18:00:00Z", Values: map[string]*float64{"temperature": ptr(5.0), "precipitationProbability": ptr(40.0), "weatherCode": ptr(4001.0)}},
+		{Time: "2024-03-02T00:00:00Z", Values: map[string]*float64{"temperature": ptr(-1.0), "weatherCode": ptr(1001.0)}},
+		{Time: "not a time", Values: map[string]*float64{"temperature": ptr(40.0)}},
+	}
+	daily := []weather.ForecastInterval{
+		{Time: "2024-03-01T00:00:00Z", Values: map[string]*float64{"temperatureMax": ptr(11.0), "temperatureMin": ptr(1.0), "precipitationProbabilityMax": ptr(70.0), "weatherCodeMax": ptr(4001.0)}},
+	}
+
+	tests := []struct {
+		name      string
+		timelines map[string][]weather.ForecastInterval
+		want      []DailySummary
+	}{
+		{"hourly grouped by day", map[string][]weather.ForecastInterval{"1h": hourly}, []DailySummary{
+			{Date: "2024-03-01", High: ptr(9.5), Low: ptr(2.0), Condition: "Rain", PrecipitationProbability: ptr(60.0)},
+			{Date: "2024-03-02", High: ptr(-1.0), Low: ptr(-1.0), Condition: "Cloudy"},
+		}},
+		{"daily timeline preferred", map[string][]weather.ForecastInterval{"1h": hourly, "1d": daily}, []DailySummary{
+			{Date: "2024-03-01", High: ptr(11.0), Low: ptr(1.0), Condition: "Rain", PrecipitationProbability: ptr(70.0)},
+		}},
+		{"values missing", map[string][]weather.ForecastInterval{"1h": {{Time: "2024-03-01T06:00:00Z", Values: map[string]*float64{}}}}, []DailySummary{
+			{Date: "2024-03-01", Condition: "Unknown"},
+		}},
+		{"no intervals", map[string][]weather.ForecastInterval{"1h": {}, "1d": {}}, []DailySummary{}},
+		{"no timelines", nil, []DailySummary{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dailySummary(ForecastResponse{City: "Toronto", Timelines: tt.timelines})
+			if got.City != "Toronto" {
+				t.Errorf("City = %q, want Toronto", got.City)
+			}
+			if !reflect.DeepEqual(got.Days, tt.want) {
+				gotJSON, _ := json.Marshal(got.Days)
+				wantJSON, _ := json.Marshal(tt.want)
+				t.Errorf("Days = %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestDailySummaryRequest(t *testing.T) {
+	setupHandler(t)
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		return jsonResponse(200, `{"timelines":{"daily":[`+
+			`{"time":"2024-03-01T00:00:00Z","values":{"temperatureMax":11,"temperatureMin":1,"weatherCodeMax":1000}}`+
+			`]},"location":{"lat":1,"lon":2,"name":"Test"}}`), nil
+	})
+
+	response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{
+		"action":    "forecast",
+		"city":      uniqueCity(t),
+		"timesteps": "1d",
+		"format":    "daily-summary",
+	}, nil))
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d; body %s", response.StatusCode, response.Body)
+	}
+	want := `{"Date":"2024-03-01","High":11,"Low":1,"Condition":"Clear"}`
+	var summary struct {
+		Days []json.RawMessage `json:"Days"`
+	}
+	if err := json.Unmarshal([]byte(response.Body), &summary); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(summary.Days) != 1 || string(summary.Days[0]) != want {
+		t.Errorf("Days = %s, want [%s]", summary.Days, want)
+	}
+}
+
+func TestDailySummaryNoDataMarshalsEmptyDays(t *testing.T) {
+	body, err := json.Marshal(dailySummary(ForecastResponse{City: "Toronto"}))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(body) != `{"City":"Toronto","Days":[]}` {
+		t.Errorf("body = %s, want an empty Days list", body)
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxDurationHeader = "X-Max-Duration-Ms"
+	minMaxDuration    = 100 * time.Millisecond
+	maxMaxDuration    = 30 * time.Second
+)
+
+// withClientDeadline bounds ctx by the client's X-Max-Duration-Ms header.
+// The Lambda deadline already on ctx still applies if it is sooner. Zero and
+// negative values are treated as if the header were absent.
+func withClientDeadline(ctx context.Context, headers map[string]string) (context.Context, context.CancelFunc) {
+	value := headerValue(headers, maxDurationHeader)
+	if value == "" {
+		return ctx, func() {}
+	}
+
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms <= 0 {
+		return ctx, func() {}
+	}
+
+	// Clamp before converting so a huge value cannot overflow the Duration
+	ms = min(ms, maxMaxDuration.Milliseconds())
+	return context.WithTimeout(ctx, clampDuration(time.Duration(ms)*time.Millisecond))
+}
+
+func clampDuration(d time.Duration) time.Duration {
+	if d < minMaxDuration {
+		return minMaxDuration
+	}
+	if d > maxMaxDuration {
+		return maxMaxDuration
+	}
+	return d
+}
+
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"weather-lambda/internal/log"
+	"weather-lambda/internal/metrics"
+)
+
+const defaultDeadlineWarnFraction = 0.8
+
+type deadlineWatchKey struct{}
+
+// deadlineWatch remembers when a request started so checkpoints can tell how
+// much of its time budget has gone.
+type deadlineWatch struct {
+	start  time.Time
+	budget time.Duration
+	warned atomic.Bool
+}
+
+// withDeadlineWatch starts watching ctx's deadline. It must be called after
+// the deadline is final, so the budget covers the whole request.
+func withDeadlineWatch(ctx context.Context) context.Context {
+	deadline, ok := ctx.Deadline()
+	if !ok || deadlineWarnFraction() == 0 {
+		return ctx
+	}
+	start := time.Now()
+	return context.WithValue(ctx, deadlineWatchKey{}, &deadlineWatch{start: start, budget: deadline.Sub(start)})
+}
+
+// checkDeadline logs a DEADLINE_WARNING line and counts it once per request
+// when the time spent at checkpoint exceeds DEADLINE_WARN_FRACTION of the
+// budget, so alarms can fire before requests start timing out.
+func checkDeadline(ctx context.Context, checkpoint string) {
+	watch, ok := ctx.Value(deadlineWatchKey{}).(*deadlineWatch)
+	if !ok {
+		return
+	}
+
+	elapsed := time.Since(watch.start)
+	if float64(elapsed) <= deadlineWarnFraction()*float64(watch.budget) || watch.warned.Swap(true) {
+		return
+	}
+	metrics.DeadlineWarnings.Inc()
+	log.WarnContext(ctx, fmt.Sprintf("DEADLINE_WARNING used %dms of %dms at %s",
+		elapsed.Milliseconds(), watch.budget.Milliseconds(), checkpoint))
+}
+
+// deadlineWarnFraction reads DEADLINE_WARN_FRACTION, defaulting to 0.8. Zero
+// disables the warning; values outside 0..1 fall back to the default.
+func deadlineWarnFraction() float64 {
+	value := os.Getenv("DEADLINE_WARN_FRACTION")
+	if value == "" {
+		return defaultDeadlineWarnFraction
+	}
+	fraction, err := strconv.ParseFloat(value, 64)
+	if err != nil || fraction < 0 || fraction > 1 {
+		return defaultDeadlineWarnFraction
+	}
+	return fraction
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCheckDeadline(t *testing.T) {
+	const counter = "weather_deadline_warnings_total"
+	tests := []struct {
+		name     string
+		fraction string
+		budget   time.Duration
+		wait     time.Duration
+		checks   int
+		want     int
+	}{
+		{"well within the budget", "", time.Second, 0, 1, 0},
+		{"near the deadline", "", 50 * time.Millisecond, 45 * time.Millisecond, 1, 1},
+		{"warned once per request", "", 50 * time.Millisecond, 45 * time.Millisecond, 3, 1},
+		{"lower fraction warns sooner", "0.1", 100 * time.Millisecond, 20 * time.Millisecond, 1, 1},
+		{"zero disables", "0", 50 * time.Millisecond, 45 * time.Millisecond, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DEADLINE_WARN_FRACTION", tt.fraction)
+			ctx, cancel := context.WithTimeout(context.Background(), tt.budget)
+			defer cancel()
+			ctx = withDeadlineWatch(ctx)
+			time.Sleep(tt.wait)
+
+			before := counterValue(t, counter)
+			for i := 0; i < tt.checks; i++ {
+				checkDeadline(ctx, "upstream")
+			}
+			if got := counterValue(t, counter) - before; got != tt.want {
+				t.Errorf("deadline warnings = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDeadlineWithoutDeadline(t *testing.T) {
+	const counter = "weather_deadline_warnings_total"
+	ctx := withDeadlineWatch(context.Background())
+	before := counterValue(t, counter)
+	checkDeadline(ctx, "response")
+	if got := counterValue(t, counter) - before; got != 0 {
+		t.Errorf("deadline warnings = %d, want none without a deadline", got)
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const debugPathHeader = "X-Debug-Path"
+
+type debugPathKey struct{}
+
+// debugPath collects the decisions taken while serving a request, in order.
+type debugPath struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+// isDebugRequest reports debug=true. Like raw mode it requires the admin API
+// key, so the internal flow is never exposed publicly.
+func isDebugRequest(request events.APIGatewayProxyRequest) bool {
+	return request.QueryStringParameters["debug"] == "true"
+}
+
+func withDebugPath(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugPathKey{}, &debugPath{})
+}
+
+// notePath records a decision. It does nothing unless the request asked for
+// its debug path.
+func notePath(ctx context.Context, step string) {
+	path, ok := ctx.Value(debugPathKey{}).(*debugPath)
+	if !ok {
+		return
+	}
+	path.mu.Lock()
+	defer path.mu.Unlock()
+	path.steps = append(path.steps, step)
+}
+
+// noteSource records whether a fallback source had a reading, e.g. cache-hit.
+func noteSource(ctx context.Context, source string, found bool) {
+	if found {
+		notePath(ctx, source+"-hit")
+	} else {
+		notePath(ctx, source+"-miss")
+	}
+}
+
+// debugPathValue renders the recorded decisions as e.g.
+// "cache-miss;db-miss;upstream-ok;persisted;cached".
+func debugPathValue(ctx context.Context) (string, bool) {
+	path, ok := ctx.Value(debugPathKey{}).(*debugPath)
+	if !ok {
+		return "", false
+	}
+	path.mu.Lock()
+	defer path.mu.Unlock()
+	return strings.Join(path.steps, ";"), true
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestDebugPath(t *testing.T) {
+	setupHandler(t)
+	t.Setenv("ADMIN_API_KEY", "admin-key")
+	calls := 0
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponse(200, realtimeBody(20, 50)), nil
+	})
+	city := uniqueCity(t)
+	admin := map[string]string{"X-Api-Key": "admin-key"}
+
+	// Subtests run in order: the first fetch fills the cache for the second
+	tests := []struct {
+		name       string
+		params     map[string]string
+		headers    map[string]string
+		wantStatus int
+		wantPath   string
+		wantHeader bool
+	}{
+		{"full upstream fetch", map[string]string{"city": city, "debug": "true"}, admin, http.StatusOK, "cache-miss;db-miss;upstream-ok;persisted;cached", true},
+		{"cache hit", map[string]string{"city": city, "debug": "true"}, admin, http.StatusOK, "cache-hit", true},
+		{"not requested", map[string]string{"city": city}, admin, http.StatusOK, "", false},
+		{"without the admin key", map[string]string{"city": city, "debug": "true"}, nil, http.StatusForbidden, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, _ := HandleRequest(context.Background(), weatherRequest(tt.params, tt.headers))
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			path, ok := response.Headers[debugPathHeader]
+			if ok != tt.wantHeader || path != tt.wantPath {
+				t.Errorf("%s = %q (set %v), want %q (set %v)", debugPathHeader, path, ok, tt.wantPath, tt.wantHeader)
+			}
+		})
+	}
+	if calls != 1 {
+		t.Errorf("upstream calls = %d, want 1", calls)
+	}
+}
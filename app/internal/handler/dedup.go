@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"weather-lambda/internal/cache"
+)
+
+func persistedKey(city string) string {
+	return cache.NamespacedKey("persisted", city)
+}
+
+// dedupWindow reads DB_DEDUP_WINDOW_SECONDS. Zero, the default, persists
+// every fresh reading.
+func dedupWindow() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("DB_DEDUP_WINDOW_SECONDS"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// recentlyPersisted reports whether this container wrote the city to the
+// store within the dedup window, in which case the write can be skipped.
+// The marker is read without counting a cache hit or miss.
+func recentlyPersisted(city string) bool {
+	if dedupWindow() == 0 {
+		return false
+	}
+	_, found := cache.Lookup(persistedKey(city))
+	return found
+}
+
+func markPersisted(city string) {
+	if window := dedupWindow(); window > 0 {
+		cache.SetCacheFor(persistedKey(city), true, window)
+	}
+}
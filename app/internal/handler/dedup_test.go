@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/db"
+)
+
+func TestDedupWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		window     string
+		wantWrites int
+	}{
+		{"disabled", "", 2},
+		{"within the window", "60", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t)
+			t.Setenv("DB_DEDUP_WINDOW_SECONDS", tt.window)
+			recorder := &recordingStore{}
+			store = recorder
+
+			city := uniqueCity(t)
+			reading := db.WeatherData{City: city, Temperature: 20, Time: time.Now().UTC().Format(time.RFC3339)}
+			for i := 0; i < 2; i++ {
+				if err := persist(context.Background(), weatherCacheKey(city, RequestOptions{}), reading); err != nil {
+					t.Fatalf("persist: %v", err)
+				}
+			}
+			if recorder.saves != tt.wantWrites {
+				t.Errorf("store writes = %d, want %d", recorder.saves, tt.wantWrites)
+			}
+		})
+	}
+}
+
+func TestDedupLeavesHitRatioAlone(t *testing.T) {
+	setupHandler(t)
+	t.Setenv("DB_DEDUP_WINDOW_SECONDS", "60")
+	city := uniqueCity(t)
+
+	before := cache.CurrentStats()
+	recentlyPersisted(city)
+	markPersisted(city)
+	if !recentlyPersisted(city) {
+		t.Errorf("a marked city is not recently persisted")
+	}
+	after := cache.CurrentStats()
+	if after.Hits != before.Hits || after.Misses != before.Misses {
+		t.Errorf("dedup lookups changed hits %d->%d, misses %d->%d", before.Hits, after.Hits, before.Misses, after.Misses)
+	}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"weather-lambda/internal/log"
+	"weather-lambda/internal/weather"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Location candidates are returned with capitalized names to match the rest
+// of the API.
+type LocationCandidate struct {
+	Name    string  `json:"Name"`
+	Region  string  `json:"Region,omitempty"`
+	Country string  `json:"Country,omitempty"`
+	Lat     float64 `json:"Lat"`
+	Lon     float64 `json:"Lon"`
+}
+
+type MultipleChoicesResponse struct {
+	City       string              `json:"City"`
+	Candidates []LocationCandidate `json:"Candidates"`
+}
+
+func isDisambiguateRequest(request events.APIGatewayProxyRequest) bool {
+	return request.QueryStringParameters["disambiguate"] == "true"
+}
+
+// disambiguate looks up every place matching the city. When more than one
+// matches it returns a 300 listing them so the client can re-request with
+// coordinates; otherwise it returns false and the lookup carries on.
+func disambiguate(ctx context.Context, city string) (events.APIGatewayProxyResponse, bool, error) {
+	candidates, err := weather.SearchLocations(ctx, city)
+	if err != nil {
+		countUpstreamError(err)
+		return events.APIGatewayProxyResponse{}, true, fmt.Errorf("%w: %w", ErrUpstream, err)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return events.APIGatewayProxyResponse{}, true, fmt.Errorf("%w: no locations match %s", ErrNotFound, city)
+	case 1:
+		return events.APIGatewayProxyResponse{}, false, nil
+	}
+
+	response := MultipleChoicesResponse{City: city}
+	for _, candidate := range candidates {
+		response.Candidates = append(response.Candidates, LocationCandidate(candidate))
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error marshalling location candidates: %v", err))
+		return events.APIGatewayProxyResponse{}, true, err
+	}
+
+	log.InfoContext(ctx, fmt.Sprintf("Returning %d location candidates for city: %s", len(candidates), city))
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusMultipleChoices,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(body),
+	}, true, nil
+}
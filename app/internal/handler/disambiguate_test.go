@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDisambiguate(t *testing.T) {
+	const springfields = `{"results":[
+		{"name":"Springfield","admin1":"Illinois","country":"United States","latitude":39.8,"longitude":-89.6},
+		{"name":"Springfield","admin1":"Missouri","country":"United States","latitude":37.2,"longitude":-93.3}
+	]}`
+
+	tests := []struct {
+		name       string
+		results    string
+		wantStatus int
+	}{
+		{"several matches", springfields, http.StatusMultipleChoices},
+		{"one match", `{"results":[{"name":"Lisbon","country":"Portugal","latitude":38.7,"longitude":-9.1}]}`, http.StatusOK},
+		{"no match", `{}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t)
+			stubUpstream(t, func(r *http.Request) (*http.Response, error) {
+				if strings.Contains(r.URL.Host, "geocoding") {
+					return jsonResponse(200, tt.results), nil
+				}
+				return jsonResponse(200, realtimeBody(20, 50)), nil
+			})
+
+			response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{
+				"city":         uniqueCity(t),
+				"disambiguate": "true",
+			}, nil))
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", response.StatusCode, tt.wantStatus, response.Body)
+			}
+			if tt.wantStatus != http.StatusMultipleChoices {
+				return
+			}
+
+			var choices MultipleChoicesResponse
+			if err := json.Unmarshal([]byte(response.Body), &choices); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			want := []LocationCandidate{
+				{Name: "Springfield", Region: "Illinois", Country: "United States", Lat: 39.8, Lon: -89.6},
+				{Name: "Springfield", Region: "Missouri", Country: "United States", Lat: 37.2, Lon: -93.3},
+			}
+			if !reflect.DeepEqual(choices.Candidates, want) {
+				t.Errorf("Candidates = %+v, want %+v", choices.Candidates, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// noContentForEmpty reports EMPTY_RESPONSE=no-content, which answers valid
+// requests that have no data with 204 instead of a 200 with an empty body.
+func noContentForEmpty() bool {
+	return os.Getenv("EMPTY_RESPONSE") == "no-content"
+}
+
+func buildNoContentResponse() events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{StatusCode: http.StatusNoContent}
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestEmptyResponseNoContent(t *testing.T) {
+	const forecast = `{"timelines":{"hourly":[{"time":"2024-01-01T00:00:00Z","values":{"temperature":20}}]},"location":{"lat":1,"lon":2,"name":"Test"}}`
+
+	tests := []struct {
+		name       string
+		mode       string
+		params     map[string]string
+		apiKey     string
+		wantStatus int
+	}{
+		{"forecast page past the end", "no-content", map[string]string{"action": "forecast", "timesteps": "1h", "offset": "5"}, "", http.StatusNoContent},
+		{"forecast page past the end by default", "", map[string]string{"action": "forecast", "timesteps": "1h", "offset": "5"}, "", http.StatusOK},
+		{"forecast with data", "no-content", map[string]string{"action": "forecast", "timesteps": "1h"}, "", http.StatusOK},
+		{"no recent cities", "no-content", map[string]string{"action": "recent"}, "key-1", http.StatusNoContent},
+		{"no recent cities by default", "", map[string]string{"action": "recent"}, "key-1", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t, "history")
+			t.Setenv("EMPTY_RESPONSE", tt.mode)
+			stubUpstream(t, func(*http.Request) (*http.Response, error) {
+				return jsonResponse(200, forecast), nil
+			})
+
+			params := map[string]string{"city": uniqueCity(t)}
+			for key, value := range tt.params {
+				params[key] = value
+			}
+			event := weatherRequest(params, nil)
+			event.RequestContext.Identity.APIKey = tt.apiKey
+
+			response, _ := HandleRequest(context.Background(), event)
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", response.StatusCode, tt.wantStatus, response.Body)
+			}
+			if tt.wantStatus == http.StatusNoContent && response.Body != "" {
+				t.Errorf("204 body = %q, want none", response.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/log"
+)
+
+const (
+	defaultErrorCacheTTL = 5 * time.Second
+	maxErrorCacheTTL     = 30 * time.Second
+)
+
+// cachedFailure is a recent upstream failure, re-served so a burst of
+// requests during an outage does not each hit the failing upstream.
+type cachedFailure struct {
+	err error
+}
+
+func errorCacheKey(city string) string {
+	return cache.NamespacedKey("error", city)
+}
+
+// errorCacheTTL reads ERROR_CACHE_TTL_SECONDS, defaulting to 5s and capped
+// at 30s. Zero disables error caching.
+func errorCacheTTL() time.Duration {
+	value := os.Getenv("ERROR_CACHE_TTL_SECONDS")
+	if value == "" {
+		return defaultErrorCacheTTL
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return defaultErrorCacheTTL
+	}
+	return min(time.Duration(seconds)*time.Second, maxErrorCacheTTL)
+}
+
+// recentFailure returns the cached upstream failure for a city, or nil.
+func recentFailure(ctx context.Context, city string) error {
+	if errorCacheTTL() == 0 {
+		return nil
+	}
+	if cached, found := cache.Lookup(errorCacheKey(city)); found {
+		if failure, ok := cached.(cachedFailure); ok {
+			log.InfoContext(ctx, fmt.Sprintf("Returning cached upstream failure for city: %s", city))
+			return failure.err
+		}
+	}
+	return nil
+}
+
+// cacheFailure remembers an upstream 5xx for a city. Client-side timeouts and
+// cancellations are not cached, as they say nothing about the upstream.
+func cacheFailure(city string, err error) {
+	ttl := errorCacheTTL()
+	if ttl == 0 || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return
+	}
+	if statusForError(err) < http.StatusInternalServerError {
+		return
+	}
+	cache.SetCacheFor(errorCacheKey(city), cachedFailure{err: err}, ttl)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"weather-lambda/internal/cache"
+)
+
+func TestErrorCache(t *testing.T) {
+	tests := []struct {
+		name           string
+		ttl            string
+		upstreamStatus int
+		wantStatus     int
+		wantCalls      int
+	}{
+		{"upstream failure is re-served", "", http.StatusInternalServerError, http.StatusBadGateway, 1},
+		{"not found is not cached", "", http.StatusNotFound, http.StatusNotFound, 2},
+		{"disabled", "0", http.StatusInternalServerError, http.StatusBadGateway, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t)
+			t.Setenv("ERROR_CACHE_TTL_SECONDS", tt.ttl)
+			calls := 0
+			stubUpstream(t, func(*http.Request) (*http.Response, error) {
+				calls++
+				return jsonResponse(tt.upstreamStatus, `{}`), nil
+			})
+
+			request := weatherRequest(map[string]string{"city": uniqueCity(t)}, nil)
+			for i := 0; i < 2; i++ {
+				response, _ := HandleRequest(context.Background(), request)
+				if response.StatusCode != tt.wantStatus {
+					t.Errorf("request %d: status = %d, want %d", i+1, response.StatusCode, tt.wantStatus)
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("upstream calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestErrorCacheLeavesHitRatioAlone(t *testing.T) {
+	setupHandler(t)
+	city := uniqueCity(t)
+	cacheFailure(city, ErrUpstream)
+
+	before := cache.CurrentStats()
+	if recentFailure(context.Background(), city) == nil || recentFailure(context.Background(), city+"-other") != nil {
+		t.Fatalf("recentFailure did not find exactly the cached failure")
+	}
+	after := cache.CurrentStats()
+	if after.Hits != before.Hits || after.Misses != before.Misses {
+		t.Errorf("error cache lookups changed hits %d->%d, misses %d->%d",
+			before.Hits, after.Hits, before.Misses, after.Misses)
+	}
+}
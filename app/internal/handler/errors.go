@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"weather-lambda/internal/weather"
+)
+
+var (
+	ErrValidation   = errors.New("invalid request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrTooLarge     = errors.New("request too large")
+	ErrUpstream     = errors.New("upstream failure")
+	ErrPersistence  = errors.New("persistence failure")
+)
+
+// statusForError maps an error from the request path to the HTTP status
+// returned to the client. Timeouts are checked first so a slow upstream or
+// database call reports 504 rather than the category it was wrapped in.
+func statusForError(err error) int {
+	var statusErr *weather.StatusError
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.As(err, &statusErr):
+		return statusForUpstream(statusErr.StatusCode)
+	case errors.Is(err, ErrUpstream):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+const defaultRetryAfter = "1"
+
+// retryAfter returns the Retry-After value for a 429 passed on from the
+// upstream, using the upstream's value when it sent one.
+func retryAfter(err error) string {
+	var statusErr *weather.StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter != "" {
+		return statusErr.RetryAfter
+	}
+	return defaultRetryAfter
+}
+
+func statusForUpstream(code int) int {
+	switch code {
+	case http.StatusNotFound:
+		return http.StatusNotFound
+	case http.StatusTooManyRequests:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusBadGateway
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"weather-lambda/internal/weather"
+)
+
+func TestStatusForError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"validation", fmt.Errorf("%w: city is required", ErrValidation), http.StatusBadRequest},
+		{"unauthorized", ErrUnauthorized, http.StatusUnauthorized},
+		{"forbidden", ErrForbidden, http.StatusForbidden},
+		{"not found", ErrNotFound, http.StatusNotFound},
+		{"too large", ErrTooLarge, http.StatusRequestEntityTooLarge},
+		{"upstream", fmt.Errorf("%w: connection reset", ErrUpstream), http.StatusBadGateway},
+		{"upstream 404", fmt.Errorf("%w: %w", ErrUpstream, &weather.StatusError{StatusCode: 404}), http.StatusNotFound},
+		{"upstream 429", fmt.Errorf("%w: %w", ErrUpstream, &weather.StatusError{StatusCode: 429}), http.StatusTooManyRequests},
+		{"upstream 500", fmt.Errorf("%w: %w", ErrUpstream, &weather.StatusError{StatusCode: 500}), http.StatusBadGateway},
+		{"upstream 401", &weather.StatusError{StatusCode: 401}, http.StatusBadGateway},
+		{"timeout", context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{"timeout wrapped in upstream", fmt.Errorf("%w: %w", ErrUpstream, context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{"timeout wrapped in persistence", fmt.Errorf("%w: %w", ErrPersistence, context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{"persistence", ErrPersistence, http.StatusInternalServerError},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statusForError(tt.err); got != tt.want {
+				t.Errorf("statusForError(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"upstream value", &weather.StatusError{StatusCode: 429, RetryAfter: "30"}, "30"},
+		{"upstream without value", &weather.StatusError{StatusCode: 429}, defaultRetryAfter},
+		{"wrapped upstream value", fmt.Errorf("%w: %w", ErrUpstream, &weather.StatusError{StatusCode: 429, RetryAfter: "30"}), "30"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryAfter(tt.err); got != tt.want {
+				t.Errorf("retryAfter = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLUpstreamIsBadGateway(t *testing.T) {
+	setupHandler(t)
+	t.Setenv("UPSTREAM_CONTENT_TYPE", "")
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		response := jsonResponse(200, `<html><body>Blocked</body></html>`)
+		response.Header.Set("Content-Type", "text/html")
+		return response, nil
+	})
+
+	response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": uniqueCity(t)}, nil))
+	if response.StatusCode != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502 for an HTML 200 from the upstream", response.StatusCode)
+	}
+}
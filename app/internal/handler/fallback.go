@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/log"
+)
+
+// Sources a current reading can come from. Those listed before upstream in
+// FALLBACK_ORDER are consulted before fetching; those after it only once the
+// upstream fetch has failed. Sources left out are never consulted.
+const (
+	sourceCache    = "cache"
+	sourceDB       = "db"
+	sourceUpstream = "upstream"
+	sourceForecast = "forecast"
+	sourceSnapshot = "snapshot"
+)
+
+var defaultFallbackOrder = []string{sourceCache, sourceDB, sourceUpstream, sourceForecast, sourceSnapshot}
+
+// fallbackOrder is resolved once per container; ValidateConfig reports a bad
+// FALLBACK_ORDER so the process can refuse to start.
+var fallbackOrder, fallbackOrderErr = parseFallbackOrder(os.Getenv("FALLBACK_ORDER"))
+
+// ValidateConfig reports configuration that cannot be served, for cmd/main.go
+// to check before starting the Lambda runtime.
+func ValidateConfig() error {
+	return fallbackOrderErr
+}
+
+func parseFallbackOrder(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return defaultFallbackOrder, nil
+	}
+
+	var order []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case sourceCache, sourceDB, sourceUpstream, sourceForecast, sourceSnapshot:
+		default:
+			return defaultFallbackOrder, fmt.Errorf("FALLBACK_ORDER: unknown source %q", name)
+		}
+		if seen[name] {
+			return defaultFallbackOrder, fmt.Errorf("FALLBACK_ORDER: %s is listed twice", name)
+		}
+		seen[name] = true
+		order = append(order, name)
+	}
+
+	if !seen[sourceUpstream] {
+		return defaultFallbackOrder, fmt.Errorf("FALLBACK_ORDER: %s must be listed", sourceUpstream)
+	}
+	return order, nil
+}
+
+// fallbackSources splits the order into the sources tried before the
+// upstream fetch and those tried after it fails.
+func fallbackSources() (before []string, after []string) {
+	for i, source := range fallbackOrder {
+		if source == sourceUpstream {
+			return fallbackOrder[:i], fallbackOrder[i+1:]
+		}
+	}
+	return nil, nil
+}
+
+// readingLookup carries what each source needs to find a reading.
+type readingLookup struct {
+	city     string
+	location string
+	cacheKey string
+	opts     RequestOptions
+}
+
+// fromSource returns a reading from one non-upstream source, if it has one.
+func fromSource(ctx context.Context, source string, lookup readingLookup) (db.WeatherData, bool) {
+	switch source {
+	case sourceCache:
+		if cachedData, found := cache.GetCache(lookup.cacheKey); found {
+			if cachedWeather, ok := cachedData.(db.WeatherData); ok {
+				log.InfoContext(ctx, fmt.Sprintf("Returning cached data for city: %s", lookup.city))
+				return cachedWeather, true
+			}
+		}
+	case sourceDB:
+		// Serve a recent stored reading to save upstream quota
+		if stored, found := freshStoredData(ctx, lookup.city); found && hasExtraFields(stored, lookup.opts) {
+			cache.SetCache(lookup.cacheKey, stored)
+			log.InfoContext(ctx, fmt.Sprintf("Returning stored data for city: %s", lookup.city))
+			return stored, true
+		}
+	case sourceForecast:
+		return currentFromForecast(ctx, lookup.city, lookup.location)
+	case sourceSnapshot:
+		return lastGoodSnapshot(ctx, lookup.city)
+	}
+	return db.WeatherData{}, false
+}
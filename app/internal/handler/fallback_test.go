@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/db"
+)
+
+func TestParseFallbackOrder(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{"", defaultFallbackOrder, false},
+		{"db, Cache ,upstream", []string{sourceDB, sourceCache, sourceUpstream}, false},
+		{"upstream,snapshot", []string{sourceUpstream, sourceSnapshot}, false},
+		{"cache,redis,upstream", defaultFallbackOrder, true},
+		{"cache,upstream,cache", defaultFallbackOrder, true},
+		{"cache,db", defaultFallbackOrder, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseFallbackOrder(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFallbackOrderIsFollowed(t *testing.T) {
+	now := time.Now().UTC()
+	tests := []struct {
+		name            string
+		order           string
+		upstreamStatus  int
+		wantStatus      int
+		wantTemperature float64
+		wantCalls       int
+	}{
+		{"cache first", "cache,db,upstream", 200, 200, 18, 0},
+		{"db first", "db,cache,upstream", 200, 200, 15, 0},
+		{"upstream first", "upstream,cache,db", 200, 200, 21.5, 1},
+		{"cache after a failed fetch", "upstream,cache", 500, 200, 18, 1},
+		{"db after a failed fetch", "upstream,db,cache", 500, 200, 15, 1},
+		{"no fallbacks after a failed fetch", "upstream", 500, 502, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := setupHandler(t)
+			t.Setenv("DB_FRESH_SECONDS", "300")
+			order, err := parseFallbackOrder(tt.order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			original := fallbackOrder
+			fallbackOrder = order
+			t.Cleanup(func() { fallbackOrder = original })
+
+			city := uniqueCity(t)
+			at := now.Format(time.RFC3339)
+			cache.SetCache(weatherCacheKey(city, RequestOptions{}), db.WeatherData{City: city, Temperature: 18, Humidity: 50, Time: at})
+			memory.Save(context.Background(), db.WeatherData{City: city, Temperature: 15, Humidity: 50, Time: at})
+
+			calls := 0
+			stubUpstream(t, func(*http.Request) (*http.Response, error) {
+				calls++
+				if tt.upstreamStatus != 200 {
+					return jsonResponse(tt.upstreamStatus, `{}`), nil
+				}
+				return jsonResponse(200, realtimeBody(21.5, 50)), nil
+			})
+
+			response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city}, nil))
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", response.StatusCode, tt.wantStatus, response.Body)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("upstream calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantStatus == 200 {
+				if got := decodeReading(t, response).Temperature; got != tt.wantTemperature {
+					t.Errorf("temperature = %v, want %v", got, tt.wantTemperature)
+				}
+			}
+		})
+	}
+}
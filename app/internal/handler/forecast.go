@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/log"
+	"weather-lambda/internal/weather"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type ForecastResponse struct {
+	City      string                                `json:"City"`
+	Timelines map[string][]weather.ForecastInterval `json:"Timelines"`
+
+	// Unavailable lists requested timesteps the upstream returned no data for
+	Unavailable []string `json:"Unavailable,omitempty"`
+
+	// Next is the query string of the following page, when there is one
+	Next string `json:"Next,omitempty"`
+}
+
+func isForecastRequest(request events.APIGatewayProxyRequest) bool {
+	return request.QueryStringParameters["action"] == "forecast"
+}
+
+func handleForecast(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	city, err := cleanCity(request.QueryStringParameters["city"])
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Invalid city parameter: %v", err))
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	// Sanitize city parameter
+	sanitizedCity := url.QueryEscape(city)
+
+	// Validate city
+	if sanitizedCity == "" {
+		log.ErrorContext(ctx, "City parameter is required")
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: city parameter is required", ErrValidation)
+	}
+
+	timesteps, err := weather.ParseTimesteps(request.QueryStringParameters["timesteps"])
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Invalid timesteps parameter: %v", err))
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	page, err := parseForecastPage(request.QueryStringParameters)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Invalid forecast paging: %v", err))
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+
+	cacheKey := cache.NamespacedKey("forecast", city) + ":" + strings.Join(timesteps, ",")
+
+	// Check cache first
+	if cachedData, found := cache.GetCache(cacheKey); found {
+		if cachedForecast, ok := cachedData.(ForecastResponse); ok {
+			log.InfoContext(ctx, fmt.Sprintf("Returning cached forecast for city: %s", sanitizedCity))
+			return buildForecastResponse(page.apply(cachedForecast, request.QueryStringParameters), request.QueryStringParameters)
+		}
+	}
+
+	forecast, err := weather.FetchForecast(ctx, sanitizedCity, timesteps)
+	recordUpstreamResult(err)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error fetching forecast: %v", err))
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: %w", ErrUpstream, err)
+	}
+
+	response := ForecastResponse{
+		City:      sanitizedCity,
+		Timelines: make(map[string][]weather.ForecastInterval, len(timesteps)),
+	}
+	for _, timestep := range timesteps {
+		intervals := forecast.Timeline(timestep)
+		if len(intervals) == 0 {
+			response.Unavailable = append(response.Unavailable, timestep)
+		}
+		response.Timelines[timestep] = intervals
+	}
+
+	if err := checkEmptyForecast(forecast, response); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Empty forecast for city %s: %v", sanitizedCity, err))
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	// Cache the full response so any page can be served from it
+	cache.SetCache(cacheKey, response)
+
+	log.InfoContext(ctx, fmt.Sprintf("Returning new forecast for city: %s", sanitizedCity))
+	return buildForecastResponse(page.apply(response, request.QueryStringParameters), request.QueryStringParameters)
+}
+
+func buildForecastResponse(response ForecastResponse, params map[string]string) (events.APIGatewayProxyResponse, error) {
+	if noContentForEmpty() && response.empty() {
+		return buildNoContentResponse(), nil
+	}
+	if isDailySummaryRequest(params) {
+		return buildResponse(dailySummary(response))
+	}
+	return buildResponse(response)
+}
+
+// empty reports whether no timeline has any intervals, either because the
+// upstream returned none or because the page starts past the last one.
+func (r ForecastResponse) empty() bool {
+	for _, intervals := range r.Timelines {
+		if len(intervals) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// checkEmptyForecast rejects a forecast with no intervals in any requested
+// timeline. An unresolved location is reported as not found; a known location
+// with no data is also a 404 unless FORECAST_EMPTY=message, which returns the
+// empty timelines with every timestep listed as unavailable.
+func checkEmptyForecast(forecast weather.ForecastResponse, response ForecastResponse) error {
+	if len(response.Unavailable) < len(response.Timelines) {
+		return nil
+	}
+
+	if forecast.Location == (weather.WeatherLocation{}) {
+		return fmt.Errorf("%w: location not found", ErrNotFound)
+	}
+	if os.Getenv("FORECAST_EMPTY") == "message" {
+		return nil
+	}
+	return fmt.Errorf("%w: no forecast data for timesteps %s", ErrNotFound, strings.Join(response.Unavailable, ","))
+}
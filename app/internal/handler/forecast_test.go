@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestEmptyForecastTimelines(t *testing.T) {
+	const (
+		location = `"location":{"lat":1,"lon":2,"name":"Test"}`
+		interval = `{"time":"2024-01-01T00:00:00Z","values":{"temperature":20}}`
+	)
+	tests := []struct {
+		name            string
+		env             string
+		body            string
+		wantStatus      int
+		wantUnavailable []string
+	}{
+		{"unknown location", "", `{"timelines":{}}`, http.StatusNotFound, nil},
+		{"no data for any timestep", "", `{"timelines":{},` + location + `}`, http.StatusNotFound, nil},
+		{"no data with message", "message", `{"timelines":{},` + location + `}`, http.StatusOK, []string{"1h", "1d"}},
+		{"one timestep empty", "", `{"timelines":{"hourly":[` + interval + `]},` + location + `}`, http.StatusOK, []string{"1d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t)
+			t.Setenv("FORECAST_EMPTY", tt.env)
+			stubUpstream(t, func(*http.Request) (*http.Response, error) {
+				return jsonResponse(200, tt.body), nil
+			})
+
+			response, err := HandleRequest(context.Background(), weatherRequest(map[string]string{
+				"action":    "forecast",
+				"city":      uniqueCity(t),
+				"timesteps": "1h,1d",
+			}, nil))
+			if err != nil {
+				t.Fatalf("HandleRequest: %v", err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", response.StatusCode, tt.wantStatus, response.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var forecast ForecastResponse
+			if err := json.Unmarshal([]byte(response.Body), &forecast); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if !reflect.DeepEqual(forecast.Unavailable, tt.wantUnavailable) {
+				t.Errorf("Unavailable = %v, want %v", forecast.Unavailable, tt.wantUnavailable)
+			}
+		})
+	}
+}
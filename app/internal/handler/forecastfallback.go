@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/log"
+	"weather-lambda/internal/weather"
+)
+
+const derivedFromForecast = "forecast"
+
+// currentFromForecast derives current conditions from the hourly forecast
+// interval nearest to now, for when the realtime endpoint fails but the
+// forecast endpoint does not. It is enabled by the forecast-fallback feature.
+// The reading is neither cached nor stored, so the next request tries realtime.
+func currentFromForecast(ctx context.Context, city string, location string) (db.WeatherData, bool) {
+	if !features.Enabled("forecast-fallback") {
+		return db.WeatherData{}, false
+	}
+
+	forecast, err := weather.FetchForecast(ctx, location, []string{"1h"})
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Forecast fallback failed: %v", err))
+		return db.WeatherData{}, false
+	}
+
+	interval, ok := nearestInterval(forecast.Timeline("1h"), time.Now())
+	if !ok || interval.Values["temperature"] == nil {
+		return db.WeatherData{}, false
+	}
+
+	data := db.WeatherData{
+		City:        city,
+		Temperature: *interval.Values["temperature"],
+		Time:        interval.Time,
+		Provider:    weather.Provider,
+		DerivedFrom: derivedFromForecast,
+		Location: &db.Location{
+			Name: forecast.Location.Name,
+			Lat:  forecast.Location.Lat,
+			Lon:  forecast.Location.Lon,
+		},
+	}
+	if humidity := interval.Values["humidity"]; humidity != nil {
+		data.Humidity = int(math.Round(*humidity))
+	}
+
+	log.InfoContext(ctx, fmt.Sprintf("Derived current conditions from forecast for city: %s", city))
+	return data, true
+}
+
+func nearestInterval(intervals []weather.ForecastInterval, now time.Time) (weather.ForecastInterval, bool) {
+	var nearest weather.ForecastInterval
+	best := time.Duration(math.MaxInt64)
+	for _, interval := range intervals {
+		at, err := time.Parse(time.RFC3339, interval.Time)
+		if err != nil {
+			continue
+		}
+		distance := now.Sub(at).Abs()
+		if distance < best {
+			nearest, best = interval, distance
+		}
+	}
+	return nearest, best != time.Duration(math.MaxInt64)
+}
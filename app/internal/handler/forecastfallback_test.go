@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"weather-lambda/internal/weather"
+)
+
+func TestNearestInterval(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	intervals := []weather.ForecastInterval{
+		{Time: "2024-01-01T10:00:00Z"},
+		{Time: "not a time"},
+		{Time: "2024-01-01T12:20:00Z"},
+		{Time: "2024-01-01T11:50:00Z"},
+	}
+	if got, ok := nearestInterval(intervals, now); !ok || got.Time != "2024-01-01T11:50:00Z" {
+		t.Errorf("nearestInterval = %q, %v; want 11:50", got.Time, ok)
+	}
+	if _, ok := nearestInterval(nil, now); ok {
+		t.Errorf("nearestInterval found an interval in an empty timeline")
+	}
+}
+
+func TestRealtimeFallsBackToForecast(t *testing.T) {
+	now := time.Now().UTC()
+	interval := func(at time.Time, temperature float64) string {
+		return fmt.Sprintf(`{"time":%q,"values":{"temperature":%v,"humidity":60}}`, at.Format(time.RFC3339), temperature)
+	}
+	forecast := `{"timelines":{"hourly":[` +
+		interval(now.Add(-2*time.Hour), 10) + "," +
+		interval(now.Add(10*time.Minute), 14) + "," +
+		interval(now.Add(3*time.Hour), 18) +
+		`]},"location":{"name":"Test"}}`
+
+	tests := []struct {
+		name       string
+		enabled    []string
+		wantStatus int
+	}{
+		{"enabled", []string{"forecast-fallback"}, http.StatusOK},
+		{"disabled", nil, http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := setupHandler(t, tt.enabled...)
+			stubUpstream(t, func(r *http.Request) (*http.Response, error) {
+				if strings.Contains(r.URL.Path, "forecast") {
+					return jsonResponse(200, forecast), nil
+				}
+				return jsonResponse(500, `{}`), nil
+			})
+
+			city := uniqueCity(t)
+			response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city}, nil))
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			reading := decodeReading(t, response)
+			if reading.Temperature != 14 || reading.Humidity != 60 || reading.DerivedFrom != derivedFromForecast {
+				t.Errorf("reading = %+v, want the nearest interval marked as derived from the forecast", reading)
+			}
+			if _, stored, _ := memory.Get(context.Background(), city); stored {
+				t.Errorf("a derived reading was stored")
+			}
+		})
+	}
+}
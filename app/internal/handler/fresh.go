@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/log"
+)
+
+// freshStoredData returns the stored reading for a city when it is younger
+// than DB_FRESH_SECONDS. The check is disabled when the variable is unset.
+func freshStoredData(ctx context.Context, city string) (db.WeatherData, bool) {
+	seconds, err := strconv.Atoi(os.Getenv("DB_FRESH_SECONDS"))
+	if err != nil || seconds <= 0 {
+		return db.WeatherData{}, false
+	}
+
+	stored, found, err := store.Get(ctx, city)
+	if err != nil || !found {
+		return db.WeatherData{}, false
+	}
+
+	observedAt, err := time.Parse(time.RFC3339, stored.Time)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error parsing stored time for city %s: %v", city, err))
+		return db.WeatherData{}, false
+	}
+
+	if time.Since(observedAt) > time.Duration(seconds)*time.Second {
+		return db.WeatherData{}, false
+	}
+	return stored, true
+}
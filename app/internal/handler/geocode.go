@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/log"
+	"weather-lambda/internal/weather"
+)
+
+// resolveLocation returns the upstream location query for a city, preferring
+// coordinates persisted by an earlier lookup so tomorrow.io skips geocoding.
+func resolveLocation(ctx context.Context, city string) (string, bool) {
+	if !features.Enabled("geocode-cache") {
+		return url.QueryEscape(city), false
+	}
+
+	geocode, found, err := db.GetGeocode(ctx, cache.NormalizeCity(city))
+	if err != nil || !found {
+		return url.QueryEscape(city), false
+	}
+
+	log.InfoContext(ctx, fmt.Sprintf("Using stored geocode for city: %s", city))
+	return url.QueryEscape(fmt.Sprintf("%g,%g", geocode.Lat, geocode.Lon)), true
+}
+
+func saveGeocode(ctx context.Context, city string, location weather.WeatherLocation) {
+	if !features.Enabled("geocode-cache") {
+		return
+	}
+
+	geocode := db.Geocode{Name: location.Name, Lat: location.Lat, Lon: location.Lon}
+	if err := db.SaveGeocode(ctx, cache.NormalizeCity(city), geocode); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error saving geocode: %v", err))
+	}
+}
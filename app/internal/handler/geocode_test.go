@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"weather-lambda/internal/cache"
+)
+
+// TestStoredGeocodeSkipsLiveLookup serves a stored geocode from a stubbed
+// DynamoDB endpoint and checks the upstream is asked for coordinates rather
+// than the city name.
+func TestStoredGeocodeSkipsLiveLookup(t *testing.T) {
+	tests := []struct {
+		name         string
+		stored       bool
+		wantLocation string
+	}{
+		{"stored geocode", true, "43.65,-79.38"},
+		{"no stored geocode", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t, "geocode-cache")
+			t.Setenv("PERSISTENCE", "")
+			t.Setenv("DB_TABLE_NAME", "weather-test")
+			t.Setenv("AWS_REGION", "us-east-1")
+			t.Setenv("AWS_ACCESS_KEY_ID", "test")
+			t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+			t.Setenv("AWS_CA_BUNDLE", "")
+
+			city := uniqueCity(t)
+			key := "GEO#" + cache.NormalizeCity(city)
+			wantLocation := tt.wantLocation
+			if wantLocation == "" {
+				wantLocation = city
+			}
+
+			var requested []string
+			var geocodeReads, geocodeWrites int
+			stubUpstream(t, func(r *http.Request) (*http.Response, error) {
+				if strings.Contains(r.URL.Host, "dynamodb") {
+					body, _ := io.ReadAll(r.Body)
+					if strings.HasSuffix(r.Header.Get("X-Amz-Target"), "PutItem") {
+						geocodeWrites++
+						return jsonResponse(200, `{}`), nil
+					}
+					geocodeReads++
+					if !tt.stored || !strings.Contains(string(body), key) {
+						return jsonResponse(200, `{}`), nil
+					}
+					return jsonResponse(200, fmt.Sprintf(`{"Item":{"City":{"S":%q},"Name":{"S":"Geocode Town"},"Lat":{"N":"43.65"},"Lon":{"N":"-79.38"},"ExpiresAt":{"N":"%d"}}}`,
+						key, time.Now().Add(time.Hour).Unix())), nil
+				}
+				requested = append(requested, r.URL.Query().Get("location"))
+				return jsonResponse(200, realtimeBody(20, 50)), nil
+			})
+
+			response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city}, nil))
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d; body %s", response.StatusCode, response.Body)
+			}
+			if geocodeReads != 1 {
+				t.Errorf("geocode reads = %d, want 1", geocodeReads)
+			}
+			if len(requested) != 1 || requested[0] != wantLocation {
+				t.Errorf("upstream locations = %q, want [%q]", requested, wantLocation)
+			}
+			if wantWrites := map[bool]int{true: 0, false: 1}[tt.stored]; geocodeWrites != wantWrites {
+				t.Errorf("geocode writes = %d, want %d", geocodeWrites, wantWrites)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"weather-lambda/internal/log"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const geoJSONContentType = "application/geo+json"
+
+type GeoJSONFeature struct {
+	Type       string                     `json:"type"`
+	Geometry   GeoJSONPoint               `json:"geometry"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+type GeoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// buildGeoJSONResponse wraps a shaped reading as a GeoJSON Feature, with the
+// location as a Point and the reading's fields as properties.
+func buildGeoJSONResponse(shaped *Response) (events.APIGatewayProxyResponse, error) {
+	location := shaped.Data.Location
+	if location == nil || (location.Lat == 0 && location.Lon == 0) {
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: no coordinates for %s", ErrNotFound, shaped.Data.City)
+	}
+
+	body, err := json.Marshal(shaped)
+	if err != nil {
+		log.Error(fmt.Sprintf("Error marshalling response data: %v", err))
+		return events.APIGatewayProxyResponse{}, err
+	}
+	var properties map[string]json.RawMessage
+	if err := json.Unmarshal(body, &properties); err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	feature := GeoJSONFeature{
+		Type: "Feature",
+		// GeoJSON positions are longitude first
+		Geometry:   GeoJSONPoint{Type: "Point", Coordinates: [2]float64{location.Lon, location.Lat}},
+		Properties: properties,
+	}
+
+	response, err := buildResponse(feature)
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+	response.Headers = map[string]string{"Content-Type": geoJSONContentType}
+	return response, nil
+}
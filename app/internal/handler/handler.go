@@ -2,41 +2,254 @@ package handler
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"weather-lambda/internal/cache"
 	"weather-lambda/internal/db"
+	"weather-lambda/internal/feature"
 	"weather-lambda/internal/log"
+	"weather-lambda/internal/metrics"
 	"weather-lambda/internal/weather"
 
 	"github.com/aws/aws-lambda-go/events"
 )
 
-func HandleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	city := request.QueryStringParameters["city"]
+// Feature flags are resolved once per container at cold start
+var features = feature.Current()
+
+// store persists readings; it is a variable so tests can swap in db.NewMemoryStore
+var store db.Store = db.DynamoStore{}
+
+// Version identifies the deployed build and is set from cmd/main.go
+var Version = "dev"
+
+func HandleRequest(ctx context.Context, event Event) (events.APIGatewayProxyResponse, error) {
+	cold := coldStart()
+
+	// Keep-warm pings return before any parsing or downstream calls
+	if isWarmup(event) {
+		log.InfoContext(ctx, "Handled warm-up ping")
+		return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+	}
+
+	request := withDefaults(event.APIGatewayProxyRequest)
+	id := requestID(request.Headers)
+	ctx = log.WithRequestID(ctx, id)
+	if isDebugRequest(request) && isAuthorized(request) {
+		ctx = withDebugPath(ctx)
+	}
+	log.InfoContext(ctx, fmt.Sprintf("Handling request: %s %s?%s", request.HTTPMethod, request.Path, log.QueryString(request.QueryStringParameters)))
+
+	response, err := handleRequest(ctx, request)
+	if err == nil {
+		if version, _ := requestedAPIVersion(request); version == apiVersionEnveloped {
+			response, err = envelope(response, id)
+		}
+	}
+	if err != nil {
+		// Every failure is mapped to a status in one place
+		log.ErrorContext(ctx, fmt.Sprintf("Request failed: %v", err))
+		response = events.APIGatewayProxyResponse{StatusCode: statusForError(err)}
+		if response.StatusCode == http.StatusTooManyRequests {
+			response.Headers = map[string]string{"Retry-After": retryAfter(err)}
+		}
+	}
+
+	if isMsgpackRequest(request) {
+		if packed, err := toMsgpack(response); err != nil {
+			log.ErrorContext(ctx, fmt.Sprintf("Error encoding msgpack response: %v", err))
+		} else {
+			response = packed
+		}
+	} else if isPrettyRequest(request) {
+		response = prettyPrint(response)
+	}
+
+	response = withServerHeaders(response)
+	response.Headers[requestIDHeader] = id
+	response.Headers[coldStartHeader] = strconv.FormatBool(cold)
+	if path, ok := debugPathValue(ctx); ok {
+		response.Headers[debugPathHeader] = path
+	}
+	response = filterHeaders(response)
+
+	log.Access(log.AccessEntry{
+		Host:   request.RequestContext.Identity.SourceIP,
+		Method: request.HTTPMethod,
+		Path:   request.Path,
+		Query:  request.QueryStringParameters,
+		Status: response.StatusCode,
+		Bytes:  bodySize(response),
+		Time:   time.Now(),
+	})
+	return response, nil
+}
+
+// bodySize is the length of the body as sent to the client.
+func bodySize(response events.APIGatewayProxyResponse) int {
+	if response.IsBase64Encoded {
+		return base64.StdEncoding.DecodedLen(len(response.Body)) - strings.Count(response.Body, "=")
+	}
+	return len(response.Body)
+}
+
+func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	if err := checkQueryLimits(request); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Rejected oversized query: %v", err))
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	if _, err := requestedAPIVersion(request); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Invalid API version: %v", err))
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	if isDebugRequest(request) && !isAuthorized(request) {
+		log.ErrorContext(ctx, "Debug path requested without a valid API key")
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: debug requires an API key", ErrForbidden)
+	}
+
+	// Serve the API description without touching any backends
+	if isSchemaRequest(request) {
+		return buildSchemaResponse(), nil
+	}
+
+	if isMetricsRequest(request) {
+		return buildMetricsResponse(), nil
+	}
+
+	if inMaintenance() {
+		log.InfoContext(ctx, "Returning maintenance response")
+		return buildMaintenanceResponse(), nil
+	}
+
+	metrics.Requests.Inc()
+	start := time.Now()
+	defer func() { metrics.RequestLatency.Observe(time.Since(start)) }()
+
+	ctx = withStageConfig(ctx, request)
+
+	// Sources consulted after a failed fetch keep the Lambda deadline, so they
+	// can still answer when it was the client's budget that ran out
+	fallbackCtx := ctx
+
+	// Honor the client's requested time budget
+	ctx, cancel := withClientDeadline(ctx, request.Headers)
+	defer cancel()
+	ctx = withDeadlineWatch(ctx)
+	defer checkDeadline(ctx, "response")
+
+	if isForecastRequest(request) {
+		return handleForecast(ctx, request)
+	}
+
+	if isRecentRequest(request) {
+		return handleRecent(ctx, request)
+	}
+
+	if isAirportRequest(request) {
+		return handleAirport(ctx, request)
+	}
+
+	if isBBoxRequest(request) {
+		return handleBBox(ctx, request)
+	}
+
+	city, err := cleanCity(request.QueryStringParameters["city"])
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Invalid city parameter: %v", err))
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	// Fall back to the viewer's location when no city is given
+	if city == "" {
+		if location, found := viewerLocation(request.Headers); found {
+			log.InfoContext(ctx, fmt.Sprintf("Using viewer location: %s", location))
+			city = location
+		}
+	}
 
 	// Sanitize city parameter
 	sanitizedCity := url.QueryEscape(city)
 
 	// Validate city
 	if sanitizedCity == "" {
-		log.Error("City parameter is required")
-		return events.APIGatewayProxyResponse{StatusCode: 400}, nil
+		log.ErrorContext(ctx, "City parameter is required")
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: city parameter is required", ErrValidation)
+	}
+
+	opts, err := parseRequestOptions(request.QueryStringParameters, defaultUnits(request))
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Invalid request options: %v", err))
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: %w", ErrValidation, err)
+	}
+	opts.Region = acceptLanguageRegion(request.Headers)
+
+	if isRawRequest(request) {
+		return handleRaw(ctx, request, sanitizedCity)
+	}
+
+	recordHistory(ctx, request, city)
+	countRequest(sanitizedCity)
+
+	if isDisambiguateRequest(request) {
+		if response, done, err := disambiguate(ctx, sanitizedCity); done {
+			return response, err
+		}
+	}
+
+	lookup := readingLookup{city: sanitizedCity, location: sanitizedCity, cacheKey: weatherCacheKey(city, opts), opts: opts}
+	before, after := fallbackSources()
+
+	for _, source := range before {
+		data, found := fromSource(ctx, source, lookup)
+		noteSource(ctx, source, found)
+		if found {
+			return buildWeatherResponse(data, opts)
+		}
 	}
 
-	// Check cache first
-	if cachedData, found := cache.GetCache(sanitizedCity); found {
-		log.Info(fmt.Sprintf("Returning cached data for city: %s", sanitizedCity))
-		return buildResponse(cachedData)
+	checkDeadline(ctx, "lookup")
+
+	// Re-serve a very recent upstream failure rather than hitting it again
+	if err := recentFailure(ctx, sanitizedCity); err != nil {
+		notePath(ctx, "error-cached")
+		return events.APIGatewayProxyResponse{}, err
 	}
 
+	location, geocoded := resolveLocation(ctx, city)
+	lookup.location = location
+
 	// Fetch weather data
-	weatherResponse, err := weather.FetchWeather(sanitizedCity)
+	weatherResponse, err := weather.FetchWeather(ctx, weather.FetchOptions{Location: location, Fields: extraFields(opts)})
+	recordUpstreamResult(err)
+	checkDeadline(ctx, "upstream")
 	if err != nil {
-		log.Error(fmt.Sprintf("Error fetching weather data: %v", err))
-		return events.APIGatewayProxyResponse{StatusCode: 500}, err
+		log.ErrorContext(ctx, fmt.Sprintf("Error fetching weather data: %v", err))
+		notePath(ctx, "upstream-error")
+		for _, source := range after {
+			data, found := fromSource(fallbackCtx, source, lookup)
+			noteSource(ctx, source, found)
+			if found {
+				return buildWeatherResponse(data, opts)
+			}
+		}
+		err = fmt.Errorf("%w: %w", ErrUpstream, err)
+		cacheFailure(sanitizedCity, err)
+		return events.APIGatewayProxyResponse{}, err
+	}
+
+	notePath(ctx, "upstream-ok")
+
+	if !geocoded {
+		saveGeocode(ctx, city, weatherResponse.Location)
 	}
 
 	weatherData := weatherResponse.Data.Values
@@ -46,25 +259,108 @@ func HandleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 		City:        sanitizedCity,
 		Temperature: weatherData.Temperature,
 		Humidity:    weatherData.Humidity,
+		Time:        weatherResponse.Data.Time,
+		Provider:    weather.Provider,
+		Degraded:    weatherResponse.Degraded,
+		Location: &db.Location{
+			Name: weatherResponse.Location.Name,
+			Lat:  weatherResponse.Location.Lat,
+			Lon:  weatherResponse.Location.Lon,
+		},
+	}
+	dbData.Severe, dbData.SeverityReasons = weather.Severity(weatherData)
+	if opts.IncludeAirQuality {
+		dbData.AirQuality = airQuality(weatherData)
+	}
+	if opts.IncludeMoonPhase {
+		dbData.MoonPhase = moonPhase(weatherData)
+	}
+	if opts.Summary {
+		dbData.Conditions = conditions(weatherData)
+	}
+	compareWithPrevious(ctx, &dbData, opts)
+
+	if dbData.Degraded {
+		notePath(ctx, "degraded")
+	} else if err := persist(ctx, lookup.cacheKey, dbData); err != nil {
+		return events.APIGatewayProxyResponse{}, err
+	}
+	sendAlert(ctx, dbData)
+
+	log.InfoContext(ctx, fmt.Sprintf("Returning new data for city: %s", sanitizedCity))
+	return buildWeatherResponse(dbData, opts)
+}
+
+// weatherCacheKey keeps readings fetched with extra fields apart from the
+// default ones so a cache hit always has what the request asked for.
+func weatherCacheKey(city string, opts RequestOptions) string {
+	return cache.NamespacedKey(extraFieldsNamespace("weather", opts), city)
+}
+
+func extraFieldsNamespace(namespace string, opts RequestOptions) string {
+	if opts.IncludeAirQuality {
+		namespace += "-aq"
+	}
+	if opts.IncludeMoonPhase {
+		namespace += "-moon"
+	}
+	if opts.IncludeTrend {
+		namespace += "-trend"
 	}
+	if opts.IncludeDelta {
+		namespace += "-delta"
+	}
+	if opts.IncludeDailyRange {
+		namespace += "-range"
+	}
+	if opts.Summary {
+		namespace += "-summary"
+	}
+	return namespace
+}
 
-	if err := db.SaveWeatherData(dbData); err != nil {
-		log.Error(fmt.Sprintf("Error saving weather data to DynamoDB: %v", err))
-		return events.APIGatewayProxyResponse{StatusCode: 500}, err
+// extraFields lists the upstream fields to request beyond the defaults.
+func extraFields(opts RequestOptions) []string {
+	var fields []string
+	if opts.IncludeAirQuality {
+		fields = append(fields, weather.AirQualityFields...)
+	}
+	if opts.IncludeMoonPhase {
+		fields = append(fields, weather.MoonPhaseFields...)
 	}
+	return fields
+}
 
-	// Cache the response
-	cache.SetCache(sanitizedCity, dbData)
+// hasExtraFields reports whether a stored reading has every extra field the
+// request asked for.
+func hasExtraFields(data db.WeatherData, opts RequestOptions) bool {
+	return (!opts.IncludeAirQuality || data.AirQuality != nil) && (!opts.IncludeMoonPhase || data.MoonPhase != nil) &&
+		(!opts.IncludeTrend || data.Trend != nil) &&
+		(!opts.IncludeDelta || data.Delta != nil) &&
+		(!opts.IncludeDailyRange || data.DailyRange != nil) &&
+		(!opts.Summary || data.Conditions != nil)
+}
 
-	log.Info(fmt.Sprintf("Returning new data for city: %s", sanitizedCity))
-	return buildResponse(dbData)
+func buildWeatherResponse(data db.WeatherData, opts RequestOptions) (events.APIGatewayProxyResponse, error) {
+	response := &Response{Data: data}
+	if err := applyTransformers(response, opts); err != nil {
+		log.Error(fmt.Sprintf("Error transforming response data: %v", err))
+		return events.APIGatewayProxyResponse{}, err
+	}
+	if opts.Summary {
+		return buildSummaryResponse(response)
+	}
+	if opts.Format == "geojson" {
+		return buildGeoJSONResponse(response)
+	}
+	return buildResponse(response)
 }
 
 func buildResponse(data interface{}) (events.APIGatewayProxyResponse, error) {
 	body, err := json.Marshal(data)
 	if err != nil {
 		log.Error(fmt.Sprintf("Error marshalling response data: %v", err))
-		return events.APIGatewayProxyResponse{StatusCode: 500}, err
+		return events.APIGatewayProxyResponse{}, err
 	}
 
 	return events.APIGatewayProxyResponse{
@@ -72,3 +368,27 @@ func buildResponse(data interface{}) (events.APIGatewayProxyResponse, error) {
 		Body:       string(body),
 	}, nil
 }
+
+// withDefaults replaces nil maps so sparse events from other sources (direct
+// invokes, test events) are handled the same as an empty API Gateway request.
+func withDefaults(request events.APIGatewayProxyRequest) events.APIGatewayProxyRequest {
+	if request.QueryStringParameters == nil {
+		request.QueryStringParameters = map[string]string{}
+	}
+	if request.Headers == nil {
+		request.Headers = map[string]string{}
+	}
+	if request.StageVariables == nil {
+		request.StageVariables = map[string]string{}
+	}
+	return request
+}
+
+func withServerHeaders(response events.APIGatewayProxyResponse) events.APIGatewayProxyResponse {
+	if response.Headers == nil {
+		response.Headers = map[string]string{}
+	}
+	response.Headers["X-Server-Version"] = Version
+	response.Headers["X-Served-At"] = time.Now().UTC().Format(time.RFC3339)
+	return response
+}
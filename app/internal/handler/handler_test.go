@@ -0,0 +1,325 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/feature"
+	"weather-lambda/internal/weather"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// stubUpstream answers every upstream call with respond for the rest of the test.
+func stubUpstream(t *testing.T, respond roundTripFunc) {
+	t.Helper()
+	original := http.DefaultTransport
+	http.DefaultTransport = respond
+	t.Cleanup(func() { http.DefaultTransport = original })
+}
+
+func jsonResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func realtimeBody(temperature float64, humidity int) string {
+	return fmt.Sprintf(`{"data":{"time":%q,"values":{"temperature":%v,"humidity":%d}},"location":{"lat":1,"lon":2,"name":"Test"}}`,
+		time.Now().UTC().Format(time.RFC3339), temperature, humidity)
+}
+
+// setupHandler keeps a test away from AWS: persistence is off, store is an
+// in-memory one and only the given features are enabled.
+func setupHandler(t *testing.T, enabled ...string) *db.MemoryStore {
+	t.Helper()
+	t.Setenv("PERSISTENCE", "none")
+	t.Setenv("WEATHER_API_KEY", "test-key")
+
+	memory := db.NewMemoryStore()
+	originalStore, originalFeatures := store, features
+	store, features = memory, feature.Parse(strings.Join(enabled, ","))
+	t.Cleanup(func() { store, features = originalStore, originalFeatures })
+	return memory
+}
+
+func weatherRequest(params map[string]string, headers map[string]string) Event {
+	return Event{APIGatewayProxyRequest: events.APIGatewayProxyRequest{
+		QueryStringParameters: params,
+		Headers:               headers,
+	}}
+}
+
+func decodeReading(t *testing.T, response events.APIGatewayProxyResponse) db.WeatherData {
+	t.Helper()
+	var reading db.WeatherData
+	if err := json.Unmarshal([]byte(response.Body), &reading); err != nil {
+		t.Fatalf("decode body %q: %v", response.Body, err)
+	}
+	return reading
+}
+
+// uniqueCity names a city no earlier test or run has cached or stored.
+func uniqueCity(t *testing.T) string {
+	return strings.ReplaceAll(fmt.Sprintf("%s-%d", t.Name(), time.Now().UnixNano()), "/", "-")
+}
+
+func TestHandleRequestSources(t *testing.T) {
+	now := time.Now().UTC()
+	reading := func(city string, temperature float64, at time.Time) db.WeatherData {
+		return db.WeatherData{City: city, Temperature: temperature, Humidity: 50, Time: at.Format(time.RFC3339)}
+	}
+
+	tests := []struct {
+		name            string
+		env             map[string]string
+		seed            func(memory *db.MemoryStore, city string)
+		upstreamStatus  int
+		wantStatus      int
+		wantTemperature float64
+		wantCalls       int
+		wantStored      bool
+	}{
+		{
+			name:            "upstream fetch",
+			upstreamStatus:  200,
+			wantStatus:      200,
+			wantTemperature: 21.5,
+			wantCalls:       1,
+			wantStored:      true,
+		},
+		{
+			name: "cache hit",
+			seed: func(_ *db.MemoryStore, city string) {
+				cache.SetCache(weatherCacheKey(city, RequestOptions{}), reading(city, 18, now))
+			},
+			upstreamStatus:  200,
+			wantStatus:      200,
+			wantTemperature: 18,
+		},
+		{
+			name: "fresh stored reading",
+			env:  map[string]string{"DB_FRESH_SECONDS": "300"},
+			seed: func(memory *db.MemoryStore, city string) {
+				memory.Save(context.Background(), reading(city, 15, now))
+			},
+			upstreamStatus:  200,
+			wantStatus:      200,
+			wantTemperature: 15,
+			wantStored:      true,
+		},
+		{
+			name: "stale stored reading",
+			env:  map[string]string{"DB_FRESH_SECONDS": "300"},
+			seed: func(memory *db.MemoryStore, city string) {
+				memory.Save(context.Background(), reading(city, 15, now.Add(-time.Hour)))
+			},
+			upstreamStatus:  200,
+			wantStatus:      200,
+			wantTemperature: 21.5,
+			wantCalls:       1,
+			wantStored:      true,
+		},
+		{name: "upstream not found", upstreamStatus: 404, wantStatus: 404, wantCalls: 1},
+		{name: "upstream rate limited", upstreamStatus: 429, wantStatus: 429, wantCalls: 1},
+		{name: "upstream failure", upstreamStatus: 500, wantStatus: 502, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := setupHandler(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			city := uniqueCity(t)
+			if tt.seed != nil {
+				tt.seed(memory, city)
+			}
+
+			calls := 0
+			stubUpstream(t, func(*http.Request) (*http.Response, error) {
+				calls++
+				if tt.upstreamStatus != 200 {
+					return jsonResponse(tt.upstreamStatus, `{}`), nil
+				}
+				return jsonResponse(200, realtimeBody(21.5, 50)), nil
+			})
+
+			response, err := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city}, nil))
+			if err != nil {
+				t.Fatalf("HandleRequest: %v", err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", response.StatusCode, tt.wantStatus, response.Body)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("upstream calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if _, stored, _ := memory.Get(context.Background(), city); stored != tt.wantStored {
+				t.Errorf("stored = %v, want %v", stored, tt.wantStored)
+			}
+			if tt.wantStatus == 200 {
+				if got := decodeReading(t, response).Temperature; got != tt.wantTemperature {
+					t.Errorf("temperature = %v, want %v", got, tt.wantTemperature)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleRequestValidation(t *testing.T) {
+	setupHandler(t)
+	calls := 0
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponse(500, `{}`), nil
+	})
+
+	tests := []struct {
+		name   string
+		params map[string]string
+	}{
+		{"missing city", map[string]string{}},
+		{"invalid UTF-8", map[string]string{"city": "bad\xff"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, _ := HandleRequest(context.Background(), weatherRequest(tt.params, nil))
+			if response.StatusCode != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", response.StatusCode)
+			}
+		})
+	}
+	if calls != 0 {
+		t.Errorf("upstream was called %d times for invalid requests", calls)
+	}
+}
+
+func TestClampDuration(t *testing.T) {
+	tests := []struct {
+		in, want time.Duration
+	}{
+		{time.Millisecond, minMaxDuration},
+		{minMaxDuration, minMaxDuration},
+		{2 * time.Second, 2 * time.Second},
+		{maxMaxDuration, maxMaxDuration},
+		{time.Hour, maxMaxDuration},
+	}
+	for _, tt := range tests {
+		if got := clampDuration(tt.in); got != tt.want {
+			t.Errorf("clampDuration(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWithClientDeadline(t *testing.T) {
+	tests := []struct {
+		name         string
+		headers      map[string]string
+		wantDeadline bool
+		wantBudget   time.Duration
+	}{
+		{"absent", nil, false, 0},
+		{"not a number", map[string]string{"X-Max-Duration-Ms": "soon"}, false, 0},
+		{"set", map[string]string{"X-Max-Duration-Ms": "500"}, true, 500 * time.Millisecond},
+		{"case-insensitive", map[string]string{"x-max-duration-ms": "500"}, true, 500 * time.Millisecond},
+		{"zero is absent", map[string]string{"X-Max-Duration-Ms": "0"}, false, 0},
+		{"negative is absent", map[string]string{"X-Max-Duration-Ms": "-500"}, false, 0},
+		{"below the minimum", map[string]string{"X-Max-Duration-Ms": "1"}, true, minMaxDuration},
+		{"above the maximum", map[string]string{"X-Max-Duration-Ms": "60000"}, true, maxMaxDuration},
+		{"would overflow a Duration", map[string]string{"X-Max-Duration-Ms": "9223372036854775"}, true, maxMaxDuration},
+		{"beyond int64", map[string]string{"X-Max-Duration-Ms": "99999999999999999999"}, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			ctx, cancel := withClientDeadline(context.Background(), tt.headers)
+			defer cancel()
+			deadline, ok := ctx.Deadline()
+			if ok != tt.wantDeadline {
+				t.Fatalf("has deadline = %v, want %v", ok, tt.wantDeadline)
+			}
+			if got := deadline.Sub(start); ok && (got < tt.wantBudget || got > tt.wantBudget+time.Second) {
+				t.Errorf("budget = %v, want %v", got, tt.wantBudget)
+			}
+		})
+	}
+}
+
+func TestFallbacksOutliveClientDeadline(t *testing.T) {
+	setupHandler(t, "forecast-fallback")
+	stubUpstream(t, func(r *http.Request) (*http.Response, error) {
+		if strings.Contains(r.URL.Path, "forecast") {
+			if err := r.Context().Err(); err != nil {
+				return nil, err
+			}
+			body := fmt.Sprintf(`{"timelines":{"hourly":[{"time":%q,"values":{"temperature":12.5,"humidity":40}}]},"location":{"name":"Test"}}`,
+				time.Now().UTC().Format(time.RFC3339))
+			return jsonResponse(200, body), nil
+		}
+		// The realtime fetch hangs until the client's budget runs out
+		<-r.Context().Done()
+		return nil, r.Context().Err()
+	})
+
+	response, err := HandleRequest(context.Background(), weatherRequest(
+		map[string]string{"city": "deadline-fallback-town"},
+		map[string]string{"X-Max-Duration-Ms": "100"},
+	))
+	if err != nil {
+		t.Fatalf("HandleRequest: %v", err)
+	}
+	if response.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200 from the forecast fallback; body %s", response.StatusCode, response.Body)
+	}
+
+	if got := decodeReading(t, response).DerivedFrom; got != derivedFromForecast {
+		t.Errorf("DerivedFrom = %q, want %q", got, derivedFromForecast)
+	}
+}
+
+func TestMissingValuesIsAnUpstreamError(t *testing.T) {
+	memory := setupHandler(t)
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		return jsonResponse(200, `{"data":{"time":"2024-01-01T00:00:00Z"},"location":{"name":"Test"}}`), nil
+	})
+
+	city := uniqueCity(t)
+	response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city}, nil))
+	if response.StatusCode != http.StatusBadGateway {
+		t.Errorf("status = %d, want 502", response.StatusCode)
+	}
+	if _, stored, _ := memory.Get(context.Background(), city); stored {
+		t.Errorf("a reading without values was stored")
+	}
+	if _, cached := cache.GetCache(weatherCacheKey(city, RequestOptions{})); cached {
+		t.Errorf("a reading without values was cached")
+	}
+}
+
+func TestStoredReadingHasProvider(t *testing.T) {
+	memory := setupHandler(t)
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		return jsonResponse(200, realtimeBody(20, 50)), nil
+	})
+
+	city := uniqueCity(t)
+	HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city}, nil))
+	stored, found, _ := memory.Get(context.Background(), city)
+	if !found || stored.Provider != weather.Provider {
+		t.Errorf("stored Provider = %q (found %v), want %q", stored.Provider, found, weather.Provider)
+	}
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"os"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// filterHeaders applies the operator's header policy to a response.
+// RESPONSE_HEADER_ALLOWLIST, when set, keeps only the named headers, and
+// RESPONSE_HEADER_DENYLIST then removes any it names. Both are
+// comma-separated and case-insensitive; by default every header is sent.
+func filterHeaders(response events.APIGatewayProxyResponse) events.APIGatewayProxyResponse {
+	allow := headerSet(os.Getenv("RESPONSE_HEADER_ALLOWLIST"))
+	deny := headerSet(os.Getenv("RESPONSE_HEADER_DENYLIST"))
+	if allow == nil && deny == nil {
+		return response
+	}
+
+	for name := range response.Headers {
+		canonical := strings.ToLower(name)
+		if (allow != nil && !allow[canonical]) || deny[canonical] {
+			delete(response.Headers, name)
+		}
+	}
+	return response
+}
+
+func headerSet(value string) map[string]bool {
+	var names map[string]bool
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name == "" {
+			continue
+		}
+		if names == nil {
+			names = map[string]bool{}
+		}
+		names[name] = true
+	}
+	return names
+}
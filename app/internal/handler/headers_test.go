@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestFilterHeaders(t *testing.T) {
+	tests := []struct {
+		name  string
+		allow string
+		deny  string
+		want  []string
+	}{
+		{"default sends everything", "", "", []string{"Content-Type", "Server", "X-Request-ID"}},
+		{"allowlist", "content-type, x-request-id", "", []string{"Content-Type", "X-Request-ID"}},
+		{"denylist", "", "X-REQUEST-ID", []string{"Content-Type", "Server"}},
+		{"deny overrides allow", "Content-Type,X-Request-ID", "x-request-id", []string{"Content-Type"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("RESPONSE_HEADER_ALLOWLIST", tt.allow)
+			t.Setenv("RESPONSE_HEADER_DENYLIST", tt.deny)
+
+			response := filterHeaders(events.APIGatewayProxyResponse{Headers: map[string]string{
+				"Content-Type": "application/json",
+				"Server":       "weather-lambda",
+				"X-Request-ID": "abc",
+			}})
+
+			var got []string
+			for name := range response.Headers {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("headers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleRequestDeniesHeaders(t *testing.T) {
+	setupHandler(t)
+	t.Setenv("RESPONSE_HEADER_DENYLIST", "X-Server-Version,"+requestIDHeader)
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		return jsonResponse(200, realtimeBody(20, 50)), nil
+	})
+
+	response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": uniqueCity(t)}, nil))
+	for _, denied := range []string{"X-Server-Version", requestIDHeader} {
+		if _, ok := response.Headers[denied]; ok {
+			t.Errorf("denied %s header was sent", denied)
+		}
+	}
+	if response.Headers["X-Served-At"] == "" {
+		t.Errorf("X-Served-At was stripped along with the denied headers")
+	}
+}
+
+func TestHandleRequestSendsServerHeaders(t *testing.T) {
+	setupHandler(t)
+	t.Setenv("RESPONSE_HEADER_ALLOWLIST", "")
+	t.Setenv("RESPONSE_HEADER_DENYLIST", "")
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		return jsonResponse(200, realtimeBody(20, 50)), nil
+	})
+
+	response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": uniqueCity(t)}, nil))
+	if got := response.Headers["X-Server-Version"]; got != Version {
+		t.Errorf("X-Server-Version = %q, want %q", got, Version)
+	}
+	if _, err := time.Parse(time.RFC3339, response.Headers["X-Served-At"]); err != nil {
+		t.Errorf("X-Served-At = %q, want an RFC 3339 time", response.Headers["X-Served-At"])
+	}
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/log"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type RecentResponse struct {
+	Cities []string `json:"cities"`
+}
+
+func isRecentRequest(request events.APIGatewayProxyRequest) bool {
+	return features.Enabled("history") && request.QueryStringParameters["action"] == "recent"
+}
+
+// clientKey returns the API key that authenticated the request, or "" when it
+// is anonymous. Keys validated by an API Gateway usage plan are preferred.
+func clientKey(request events.APIGatewayProxyRequest) string {
+	if key := request.RequestContext.Identity.APIKey; key != "" {
+		return key
+	}
+	if isAuthorized(request) {
+		return headerValue(request.Headers, apiKeyHeader)
+	}
+	return ""
+}
+
+// recordHistory notes the city against the caller's API key. It is
+// best-effort: a failed write is logged and never fails the request.
+func recordHistory(ctx context.Context, request events.APIGatewayProxyRequest, city string) {
+	if !features.Enabled("history") {
+		return
+	}
+
+	key := clientKey(request)
+	if key == "" {
+		return
+	}
+
+	if err := db.RecordCity(ctx, key, city); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error recording city history: %v", err))
+	}
+}
+
+func handleRecent(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	key := clientKey(request)
+	if key == "" {
+		log.ErrorContext(ctx, "Recent cities requested without an API key")
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: recent cities require an API key", ErrUnauthorized)
+	}
+
+	limit := db.MaxRecentCities
+	if value := request.QueryStringParameters["limit"]; value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > db.MaxRecentCities {
+			return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: limit must be between 1 and %d", ErrValidation, db.MaxRecentCities)
+		}
+		limit = parsed
+	}
+
+	cities, err := db.GetRecentCities(ctx, key)
+	if err != nil {
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: %w", ErrPersistence, err)
+	}
+
+	if noContentForEmpty() && len(cities) == 0 {
+		return buildNoContentResponse(), nil
+	}
+
+	return buildResponse(RecentResponse{Cities: cities[:min(limit, len(cities))]})
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestRecentCities(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    []string
+		apiKey     string
+		limit      string
+		wantStatus int
+	}{
+		{"feature off", nil, "key-1", "", http.StatusBadRequest},
+		{"anonymous", []string{"history"}, "", "", http.StatusUnauthorized},
+		{"authenticated", []string{"history"}, "key-1", "", http.StatusOK},
+		{"limit in range", []string{"history"}, "key-1", "3", http.StatusOK},
+		{"limit too large", []string{"history"}, "key-1", "11", http.StatusBadRequest},
+		{"limit not a number", []string{"history"}, "key-1", "all", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t, tt.enabled...)
+			params := map[string]string{"action": "recent"}
+			if tt.limit != "" {
+				params["limit"] = tt.limit
+			}
+			event := weatherRequest(params, nil)
+			event.RequestContext.Identity = events.APIGatewayRequestIdentity{APIKey: tt.apiKey}
+
+			response, _ := HandleRequest(context.Background(), event)
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", response.StatusCode, tt.wantStatus, response.Body)
+			}
+			if tt.wantStatus == http.StatusOK {
+				var recent RecentResponse
+				if err := json.Unmarshal([]byte(response.Body), &recent); err != nil || recent.Cities == nil {
+					t.Errorf("body = %q, want a cities list", response.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	var request events.APIGatewayProxyRequest
+	if key := clientKey(request); key != "" {
+		t.Errorf("anonymous clientKey = %q, want none", key)
+	}
+	request.RequestContext.Identity.APIKey = "usage-plan-key"
+	if key := clientKey(request); key != "usage-plan-key" {
+		t.Errorf("clientKey = %q, want the usage plan key", key)
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/log"
+	"weather-lambda/internal/snapshot"
+)
+
+const (
+	snapshotTimeout         = 5 * time.Second
+	defaultSnapshotInterval = 15 * time.Minute
+)
+
+func snapshotKey(city string) string {
+	return cache.NamespacedKey("snapshot", city)
+}
+
+// snapshotInterval reads SNAPSHOT_MIN_AGE_SECONDS, the age a city's snapshot
+// must reach before a fresh reading replaces it. Zero snapshots every fresh
+// reading.
+func snapshotInterval() time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv("SNAPSHOT_MIN_AGE_SECONDS"))
+	if err != nil || seconds < 0 {
+		return defaultSnapshotInterval
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// saveSnapshot refreshes the S3 snapshot for a fresh reading once this
+// container's last snapshot of the city is older than the snapshot interval.
+// The write runs in the background with its own timeout so it never delays
+// the response, and a failure is only logged.
+func saveSnapshot(ctx context.Context, data db.WeatherData) {
+	if !snapshot.Enabled() {
+		return
+	}
+	if _, found := cache.Lookup(snapshotKey(data.City)); found {
+		notePath(ctx, "snapshot-fresh")
+		return
+	}
+
+	go func() {
+		snapshotCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), snapshotTimeout)
+		defer cancel()
+		if err := snapshot.Save(snapshotCtx, data); err != nil {
+			log.ErrorContext(snapshotCtx, fmt.Sprintf("Error saving snapshot: %v", err))
+			return
+		}
+		if interval := snapshotInterval(); interval > 0 {
+			cache.SetCacheFor(snapshotKey(data.City), true, interval)
+		}
+	}()
+}
+
+// lastGoodSnapshot is the final fallback when the cache, the store and the
+// upstream all failed to produce a reading.
+func lastGoodSnapshot(ctx context.Context, city string) (db.WeatherData, bool) {
+	if !snapshot.Enabled() {
+		return db.WeatherData{}, false
+	}
+
+	data, found, err := snapshot.Load(ctx, city)
+	if err != nil || !found {
+		return db.WeatherData{}, false
+	}
+
+	log.InfoContext(ctx, fmt.Sprintf("Serving last good snapshot for city: %s", city))
+	return data, true
+}
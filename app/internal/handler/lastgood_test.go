@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/db"
+)
+
+func TestSnapshotFallbackOrdering(t *testing.T) {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name            string
+		order           []string
+		storedFresh     bool
+		wantTemperature float64
+	}{
+		{"snapshot after upstream fails", defaultFallbackOrder, false, 5},
+		{"db listed before snapshot", []string{sourceCache, sourceUpstream, sourceDB, sourceSnapshot}, true, 15},
+		{"snapshot listed before db", []string{sourceCache, sourceUpstream, sourceSnapshot, sourceDB}, true, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := setupHandler(t)
+			useSnapshotBucket(t)
+			t.Setenv("DB_FRESH_SECONDS", "300")
+			originalOrder := fallbackOrder
+			fallbackOrder = tt.order
+			t.Cleanup(func() { fallbackOrder = originalOrder })
+
+			city := uniqueCity(t)
+			if tt.storedFresh {
+				memory.Save(context.Background(), db.WeatherData{City: city, Temperature: 15, Time: now})
+			}
+			snapshot, _ := json.Marshal(db.WeatherData{City: city, Temperature: 5, Time: now})
+			stubUpstream(t, func(r *http.Request) (*http.Response, error) {
+				if strings.Contains(r.URL.Host, "s3") {
+					return jsonResponse(200, string(snapshot)), nil
+				}
+				return jsonResponse(500, `{}`), nil
+			})
+
+			response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city}, nil))
+			if response.StatusCode != 200 {
+				t.Fatalf("status = %d, want 200", response.StatusCode)
+			}
+			if got := decodeReading(t, response).Temperature; got != tt.wantTemperature {
+				t.Errorf("temperature = %v, want %v", got, tt.wantTemperature)
+			}
+		})
+	}
+}
+
+func useSnapshotBucket(t *testing.T) {
+	t.Helper()
+	t.Setenv("SNAPSHOT_BUCKET", "snapshots-test")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CA_BUNDLE", "")
+}
+
+func TestSnapshotDoesNotDelayResponse(t *testing.T) {
+	setupHandler(t)
+	useSnapshotBucket(t)
+	t.Setenv("SNAPSHOT_MIN_AGE_SECONDS", "0")
+
+	release := make(chan struct{})
+	puts := make(chan string, 1)
+	stubUpstream(t, func(r *http.Request) (*http.Response, error) {
+		if strings.Contains(r.URL.Host, "s3") {
+			<-release
+			puts <- r.URL.Path
+			return jsonResponse(200, `{}`), nil
+		}
+		return jsonResponse(200, realtimeBody(20, 50)), nil
+	})
+
+	response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": uniqueCity(t)}, nil))
+	if response.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200", response.StatusCode)
+	}
+
+	// The response came back while the snapshot write was still blocked
+	close(release)
+	select {
+	case <-puts:
+	case <-time.After(time.Second):
+		t.Fatal("snapshot was never written")
+	}
+}
+
+func TestSnapshotInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		minAge   string
+		wantPuts int
+	}{
+		{"second reading within the interval is skipped", "3600", 1},
+		{"zero snapshots every reading", "0", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t)
+			useSnapshotBucket(t)
+			t.Setenv("SNAPSHOT_MIN_AGE_SECONDS", tt.minAge)
+
+			puts := make(chan string, 2)
+			stubUpstream(t, func(r *http.Request) (*http.Response, error) {
+				puts <- r.URL.Path
+				return jsonResponse(200, `{}`), nil
+			})
+
+			city := uniqueCity(t)
+			got := 0
+			for i := 0; i < 2; i++ {
+				saveSnapshot(context.Background(), db.WeatherData{City: city, Temperature: 20, Time: "2024-03-01 12:00"})
+				select {
+				case <-puts:
+					got++
+				case <-time.After(200 * time.Millisecond):
+				}
+				// The marker is set once the write returns
+				waitFor(t, func() bool {
+					_, found := cache.Lookup(snapshotKey(city))
+					return found || tt.minAge == "0"
+				})
+			}
+			if got != tt.wantPuts {
+				t.Errorf("snapshot writes = %d, want %d", got, tt.wantPuts)
+			}
+		})
+	}
+}
+
+func waitFor(t *testing.T, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	defaultMaxQueryLength = 2048
+	defaultMaxParamLength = 256
+)
+
+// checkQueryLimits bounds the query string before anything is parsed. The
+// combined length of names and values is capped by MAX_QUERY_LENGTH (413)
+// and any single value by MAX_PARAM_LENGTH (400).
+func checkQueryLimits(request events.APIGatewayProxyRequest) error {
+	maxQuery := envLimit("MAX_QUERY_LENGTH", defaultMaxQueryLength)
+	maxParam := envLimit("MAX_PARAM_LENGTH", defaultMaxParamLength)
+
+	total := 0
+	for name, value := range request.QueryStringParameters {
+		if len(value) > maxParam {
+			return fmt.Errorf("%w: %s exceeds %d characters", ErrValidation, name, maxParam)
+		}
+		total += len(name) + len(value)
+	}
+	if total > maxQuery {
+		return fmt.Errorf("%w: query string exceeds %d characters", ErrTooLarge, maxQuery)
+	}
+	return nil
+}
+
+func envLimit(envVar string, fallback int) int {
+	limit, err := strconv.Atoi(os.Getenv(envVar))
+	if err != nil || limit <= 0 {
+		return fallback
+	}
+	return limit
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestQueryLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		params     map[string]string
+		wantStatus int
+	}{
+		{"long city", nil, map[string]string{"city": strings.Repeat("a", defaultMaxParamLength+1)}, http.StatusBadRequest},
+		{"long cities list", nil, map[string]string{"cities": strings.Repeat("Paris,", 50)}, http.StatusBadRequest},
+		{"long query", map[string]string{"MAX_QUERY_LENGTH": "40"}, map[string]string{
+			"city":   "Paris",
+			"fields": "temperature,humidity",
+			"units":  "metric",
+		}, http.StatusRequestEntityTooLarge},
+		{"raised param limit", map[string]string{"MAX_PARAM_LENGTH": "500"}, map[string]string{"city": strings.Repeat("a", 300)}, http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			calls := 0
+			stubUpstream(t, func(*http.Request) (*http.Response, error) {
+				calls++
+				return jsonResponse(500, `{}`), nil
+			})
+
+			response, _ := HandleRequest(context.Background(), weatherRequest(tt.params, nil))
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if rejected := tt.wantStatus != http.StatusBadGateway; rejected && calls != 0 {
+				t.Errorf("upstream was called %d times for a rejected query", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	defaultMaintenanceMessage    = "The weather service is down for maintenance."
+	defaultMaintenanceRetryAfter = "300"
+)
+
+type MaintenanceResponse struct {
+	Status     string `json:"status"`
+	Message    string `json:"message"`
+	RetryAfter string `json:"retryAfter"`
+}
+
+// inMaintenance reports the maintenance feature, which takes the endpoint
+// offline without undeploying. Warm-up pings, the schema and metrics are
+// still served, as they touch no backends.
+func inMaintenance() bool {
+	return features.Enabled("maintenance")
+}
+
+// buildMaintenanceResponse answers with MAINTENANCE_STATUS (default 503),
+// MAINTENANCE_MESSAGE and a Retry-After of MAINTENANCE_RETRY_AFTER seconds.
+func buildMaintenanceResponse() events.APIGatewayProxyResponse {
+	status, err := strconv.Atoi(os.Getenv("MAINTENANCE_STATUS"))
+	if err != nil || status < 400 || status > 599 {
+		status = http.StatusServiceUnavailable
+	}
+	message := os.Getenv("MAINTENANCE_MESSAGE")
+	if message == "" {
+		message = defaultMaintenanceMessage
+	}
+	retryAfter := os.Getenv("MAINTENANCE_RETRY_AFTER")
+	if _, err := strconv.Atoi(retryAfter); err != nil {
+		retryAfter = defaultMaintenanceRetryAfter
+	}
+
+	body, _ := json.Marshal(MaintenanceResponse{Status: "maintenance", Message: message, RetryAfter: retryAfter})
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "application/json", "Retry-After": retryAfter},
+		Body:       string(body),
+	}
+}
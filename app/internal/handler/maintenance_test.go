@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestMaintenanceResponse(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            map[string]string
+		wantStatus     int
+		wantRetryAfter string
+		wantMessage    string
+	}{
+		{"defaults", nil, http.StatusServiceUnavailable, defaultMaintenanceRetryAfter, defaultMaintenanceMessage},
+		{"configured", map[string]string{"MAINTENANCE_STATUS": "502", "MAINTENANCE_RETRY_AFTER": "60", "MAINTENANCE_MESSAGE": "Back soon"}, http.StatusBadGateway, "60", "Back soon"},
+		{"invalid values fall back", map[string]string{"MAINTENANCE_STATUS": "200", "MAINTENANCE_RETRY_AFTER": "later"}, http.StatusServiceUnavailable, defaultMaintenanceRetryAfter, defaultMaintenanceMessage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t, "maintenance")
+			for _, name := range []string{"MAINTENANCE_STATUS", "MAINTENANCE_RETRY_AFTER", "MAINTENANCE_MESSAGE"} {
+				t.Setenv(name, tt.env[name])
+			}
+			calls := 0
+			stubUpstream(t, func(*http.Request) (*http.Response, error) {
+				calls++
+				return jsonResponse(200, realtimeBody(20, 50)), nil
+			})
+
+			response, err := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": uniqueCity(t)}, nil))
+			if err != nil {
+				t.Fatalf("HandleRequest: %v", err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+			if got := response.Headers["Retry-After"]; got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+			var body MaintenanceResponse
+			if err := json.Unmarshal([]byte(response.Body), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Status != "maintenance" || body.Message != tt.wantMessage || body.RetryAfter != tt.wantRetryAfter {
+				t.Errorf("body = %+v, want the maintenance message and retry", body)
+			}
+			if calls != 0 {
+				t.Errorf("upstream calls = %d, want none during maintenance", calls)
+			}
+		})
+	}
+}
+
+func TestMaintenanceStillServes(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+	}{
+		{"schema", Event{APIGatewayProxyRequest: events.APIGatewayProxyRequest{Path: "/openapi.json"}}},
+		{"metrics", weatherRequest(map[string]string{"action": "metrics"}, nil)},
+		{"warm-up ping", Event{Warmup: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t, "maintenance", "metrics")
+			response, err := HandleRequest(context.Background(), tt.event)
+			if err != nil {
+				t.Fatalf("HandleRequest: %v", err)
+			}
+			if response.StatusCode != http.StatusOK {
+				t.Errorf("status = %d, want 200 during maintenance", response.StatusCode)
+			}
+		})
+	}
+}
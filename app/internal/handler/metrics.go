@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"errors"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/metrics"
+	"weather-lambda/internal/weather"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func isMetricsRequest(request events.APIGatewayProxyRequest) bool {
+	return features.Enabled("metrics") && request.QueryStringParameters["action"] == "metrics"
+}
+
+func buildMetricsResponse() events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": "text/plain; version=0.0.4"},
+		Body:       metrics.Render(),
+	}
+}
+
+// recordUpstreamResult feeds a fetch outcome into the upstream health window
+// behind the TTL floor and counts it if it failed. The local quota backoff
+// never reached the upstream, so it is left out of both.
+func recordUpstreamResult(err error) {
+	if errors.Is(err, weather.ErrQuotaBackoff) {
+		return
+	}
+	cache.RecordUpstreamResult(err)
+	if err != nil {
+		metrics.UpstreamErrors.Inc()
+	}
+}
+
+// countUpstreamError counts a failed fetch that is kept out of the health
+// window, leaving out the local quota backoff.
+func countUpstreamError(err error) {
+	if err != nil && !errors.Is(err, weather.ErrQuotaBackoff) {
+		metrics.UpstreamErrors.Inc()
+	}
+}
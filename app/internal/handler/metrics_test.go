@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"weather-lambda/internal/metrics"
+	"weather-lambda/internal/weather"
+)
+
+func TestMetricsRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    []string
+		wantMetric bool
+	}{
+		{"enabled", []string{"metrics"}, true},
+		{"disabled", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t, tt.enabled...)
+			stubUpstream(t, func(*http.Request) (*http.Response, error) {
+				return jsonResponse(500, `{}`), nil
+			})
+
+			response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"action": "metrics"}, nil))
+			isMetrics := strings.HasPrefix(response.Headers["Content-Type"], "text/plain; version=0.0.4")
+			if isMetrics != tt.wantMetric {
+				t.Fatalf("metrics served = %v, want %v (status %d)", isMetrics, tt.wantMetric, response.StatusCode)
+			}
+			if tt.wantMetric && !strings.Contains(response.Body, "weather_requests_total") {
+				t.Errorf("body is missing weather_requests_total:\n%s", response.Body)
+			}
+		})
+	}
+}
+
+// counterValue reads a counter from the exposition output.
+func counterValue(t *testing.T, name string) int {
+	t.Helper()
+	for _, line := range strings.Split(metrics.Render(), "\n") {
+		if value, found := strings.CutPrefix(line, name+" "); found {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				t.Fatalf("counter %s has value %q", name, value)
+			}
+			return n
+		}
+	}
+	t.Fatalf("counter %s not rendered", name)
+	return 0
+}
+
+func TestRecordUpstreamResultSkipsQuotaBackoff(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		count int
+	}{
+		{"success", nil, 0},
+		{"upstream failure", &weather.StatusError{StatusCode: 500}, 1},
+		{"upstream 429", &weather.StatusError{StatusCode: 429}, 1},
+		{"local quota backoff", fmt.Errorf("%w: %w", weather.ErrQuotaBackoff, &weather.StatusError{StatusCode: 429}), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := counterValue(t, "weather_upstream_errors_total")
+			recordUpstreamResult(tt.err)
+			if got := counterValue(t, "weather_upstream_errors_total") - before; got != tt.count {
+				t.Errorf("upstream errors counted = %d, want %d", got, tt.count)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/weather"
+)
+
+func moonPhase(values weather.WeatherDataValues) *db.MoonPhase {
+	phase := &db.MoonPhase{Phase: values.MoonPhase, Label: "Unknown"}
+	if values.MoonPhase != nil {
+		phase.Label = weather.MoonPhaseLabel(*values.MoonPhase)
+	}
+	return phase
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIncludeMoonPhase(t *testing.T) {
+	tests := []struct {
+		name      string
+		moonPhase string
+		wantLabel string
+	}{
+		{"known phase", `,"moonPhase":3`, "Waxing Gibbous"},
+		{"out of range", `,"moonPhase":9`, "Unknown"},
+		{"missing", ``, "Unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t)
+			var fields string
+			stubUpstream(t, func(r *http.Request) (*http.Response, error) {
+				fields = r.URL.Query().Get("fields")
+				body := fmt.Sprintf(`{"data":{"time":%q,"values":{"temperature":10,"humidity":60%s}},"location":{"name":"Test"}}`,
+					time.Now().UTC().Format(time.RFC3339), tt.moonPhase)
+				return jsonResponse(200, body), nil
+			})
+
+			params := map[string]string{"city": uniqueCity(t), "includeMoonPhase": "true"}
+			response, _ := HandleRequest(context.Background(), weatherRequest(params, nil))
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d; body %s", response.StatusCode, response.Body)
+			}
+			if !strings.Contains(fields, "moonPhase") {
+				t.Errorf("upstream fields = %q, want moonPhase requested", fields)
+			}
+			if phase := decodeReading(t, response).MoonPhase; phase == nil || phase.Label != tt.wantLabel {
+				t.Errorf("MoonPhase = %+v, want label %q", phase, tt.wantLabel)
+			}
+		})
+	}
+}
+
+func TestMoonPhaseHasItsOwnCacheEntry(t *testing.T) {
+	setupHandler(t)
+	calls := 0
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponse(200, realtimeBody(10, 60)), nil
+	})
+
+	city := uniqueCity(t)
+	HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city}, nil))
+	response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city, "includeMoonPhase": "true"}, nil))
+	if calls != 2 {
+		t.Errorf("upstream calls = %d, want 2; a plain cached reading has no moon phase", calls)
+	}
+	if decodeReading(t, response).MoonPhase == nil {
+		t.Errorf("MoonPhase missing after a plain reading was cached")
+	}
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const msgpackContentType = "application/msgpack"
+
+// isMsgpackRequest reports whether the client asked for MessagePack, with
+// format=msgpack or an Accept header naming it.
+func isMsgpackRequest(request events.APIGatewayProxyRequest) bool {
+	return request.QueryStringParameters["format"] == "msgpack" ||
+		strings.Contains(headerValue(request.Headers, "Accept"), msgpackContentType)
+}
+
+// toMsgpack re-encodes a JSON response body as base64 MessagePack for
+// bandwidth-constrained clients. Working from the JSON keeps field names,
+// projection and transforms identical across both formats. Non-2xx
+// responses and non-JSON bodies are left alone.
+func toMsgpack(response events.APIGatewayProxyResponse) (events.APIGatewayProxyResponse, error) {
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response, nil
+	}
+	if response.Body == "" || !isJSONResponse(response) {
+		return response, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(response.Body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return response, err
+	}
+
+	var packed bytes.Buffer
+	if err := writeMsgpack(&packed, value); err != nil {
+		return response, err
+	}
+
+	if response.Headers == nil {
+		response.Headers = map[string]string{}
+	}
+	response.Headers["Content-Type"] = msgpackContentType
+	response.Body = base64.StdEncoding.EncodeToString(packed.Bytes())
+	response.IsBase64Encoded = true
+	return response, nil
+}
+
+// writeMsgpack encodes a value decoded from JSON with UseNumber. Map keys
+// are written in sorted order so the output is deterministic.
+func writeMsgpack(b *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		b.WriteByte(0xc0)
+	case bool:
+		if v {
+			b.WriteByte(0xc3)
+		} else {
+			b.WriteByte(0xc2)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			writeMsgpackInt(b, i)
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		b.WriteByte(0xcb)
+		binary.Write(b, binary.BigEndian, math.Float64bits(f))
+	case string:
+		writeMsgpackHeader(b, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		b.WriteString(v)
+	case []interface{}:
+		writeMsgpackHeader(b, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := writeMsgpack(b, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		writeMsgpackHeader(b, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for _, key := range keys {
+			writeMsgpack(b, key)
+			if err := writeMsgpack(b, v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cannot encode %T as msgpack", value)
+	}
+	return nil
+}
+
+func writeMsgpackInt(b *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i < 128:
+		b.WriteByte(byte(i))
+	case i >= -32 && i < 0:
+		b.WriteByte(byte(0xe0 | (i + 32)))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		b.WriteByte(0xd0)
+		b.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		b.WriteByte(0xd1)
+		binary.Write(b, binary.BigEndian, int16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		b.WriteByte(0xd2)
+		binary.Write(b, binary.BigEndian, int32(i))
+	default:
+		b.WriteByte(0xd3)
+		binary.Write(b, binary.BigEndian, i)
+	}
+}
+
+// writeMsgpackHeader writes a length prefix using the fixed form below
+// fixedLimit, then the 8, 16 or 32-bit form. A zero code skips that form.
+func writeMsgpackHeader(b *bytes.Buffer, n int, fixed byte, fixedLimit int, code8, code16, code32 byte) {
+	switch {
+	case n < fixedLimit:
+		b.WriteByte(fixed | byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		b.WriteByte(code8)
+		b.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		b.WriteByte(code16)
+		binary.Write(b, binary.BigEndian, uint16(n))
+	default:
+		b.WriteByte(code32)
+		binary.Write(b, binary.BigEndian, uint32(n))
+	}
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// readMsgpack decodes the subset of MessagePack that writeMsgpack emits.
+func readMsgpack(r *bytes.Reader) (interface{}, error) {
+	code, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	length := func(size int) int {
+		buf := make([]byte, size)
+		r.Read(buf)
+		switch size {
+		case 1:
+			return int(buf[0])
+		case 2:
+			return int(binary.BigEndian.Uint16(buf))
+		}
+		return int(binary.BigEndian.Uint32(buf))
+	}
+	readString := func(n int) string {
+		buf := make([]byte, n)
+		r.Read(buf)
+		return string(buf)
+	}
+	readArray := func(n int) ([]interface{}, error) {
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readMsgpack(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	readMap := func(n int) (map[string]interface{}, error) {
+		m := make(map[string]interface{}, n)
+		for i := 0; i < n; i++ {
+			key, err := readMsgpack(r)
+			if err != nil {
+				return nil, err
+			}
+			if m[key.(string)], err = readMsgpack(r); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+
+	switch {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code&0xe0 == 0xa0:
+		return readString(int(code & 0x1f)), nil
+	case code&0xf0 == 0x90:
+		return readArray(int(code & 0x0f))
+	case code&0xf0 == 0x80:
+		return readMap(int(code & 0x0f))
+	}
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2, 0xc3:
+		return code == 0xc3, nil
+	case 0xcb:
+		var bits uint64
+		binary.Read(r, binary.BigEndian, &bits)
+		return math.Float64frombits(bits), nil
+	case 0xd0:
+		var v int8
+		binary.Read(r, binary.BigEndian, &v)
+		return int64(v), nil
+	case 0xd1:
+		var v int16
+		binary.Read(r, binary.BigEndian, &v)
+		return int64(v), nil
+	case 0xd2:
+		var v int32
+		binary.Read(r, binary.BigEndian, &v)
+		return int64(v), nil
+	case 0xd3:
+		var v int64
+		binary.Read(r, binary.BigEndian, &v)
+		return v, nil
+	case 0xd9:
+		return readString(length(1)), nil
+	case 0xda:
+		return readString(length(2)), nil
+	case 0xdc:
+		return readArray(length(2))
+	case 0xde:
+		return readMap(length(2))
+	}
+	return nil, fmt.Errorf("unexpected msgpack code %#x", code)
+}
+
+func TestToMsgpackRoundTrip(t *testing.T) {
+	body := `{"City":"Zürich","Temperature":-3.5,"Humidity":81,"Severe":false,"Location":null,` +
+		`"SeverityReasons":["a","b"],"Big":100000,"Negative":-200,"Long":"` + string(bytes.Repeat([]byte("x"), 40)) + `"}`
+	response, err := toMsgpack(events.APIGatewayProxyResponse{StatusCode: 200, Body: body})
+	if err != nil {
+		t.Fatalf("toMsgpack: %v", err)
+	}
+	if !response.IsBase64Encoded || response.Headers["Content-Type"] != msgpackContentType {
+		t.Fatalf("response is not marked as base64 msgpack: %+v", response)
+	}
+
+	packed, err := base64.StdEncoding.DecodeString(response.Body)
+	if err != nil {
+		t.Fatalf("body is not base64: %v", err)
+	}
+	decoded, err := readMsgpack(bytes.NewReader(packed))
+	if err != nil {
+		t.Fatalf("decode msgpack: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"City":            "Zürich",
+		"Temperature":     -3.5,
+		"Humidity":        int64(81),
+		"Severe":          false,
+		"Location":        nil,
+		"SeverityReasons": []interface{}{"a", "b"},
+		"Big":             int64(100000),
+		"Negative":        int64(-200),
+		"Long":            string(bytes.Repeat([]byte("x"), 40)),
+	}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("decoded = %#v, want %#v", decoded, want)
+	}
+}
+
+func TestToMsgpackLeavesOthersAlone(t *testing.T) {
+	tests := []struct {
+		name     string
+		response events.APIGatewayProxyResponse
+	}{
+		{"error status", events.APIGatewayProxyResponse{StatusCode: http.StatusBadRequest, Body: `{"message":"bad"}`}},
+		{"multiple choices", events.APIGatewayProxyResponse{StatusCode: http.StatusMultipleChoices, Body: `{"City":"x"}`}},
+		{"empty body", events.APIGatewayProxyResponse{StatusCode: http.StatusOK}},
+		{"not JSON", events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: "# HELP", Headers: map[string]string{"Content-Type": "text/plain"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toMsgpack(tt.response)
+			if err != nil || !reflect.DeepEqual(got, tt.response) {
+				t.Errorf("toMsgpack = %+v, %v; want the response unchanged", got, err)
+			}
+		})
+	}
+}
+
+func TestMsgpackNegotiation(t *testing.T) {
+	setupHandler(t)
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		return jsonResponse(200, realtimeBody(20, 50)), nil
+	})
+
+	tests := []struct {
+		name    string
+		params  map[string]string
+		headers map[string]string
+	}{
+		{"format parameter", map[string]string{"format": "msgpack"}, nil},
+		{"accept header", nil, map[string]string{"Accept": "application/msgpack"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]string{"city": uniqueCity(t)}
+			for key, value := range tt.params {
+				params[key] = value
+			}
+			response, _ := HandleRequest(context.Background(), weatherRequest(params, tt.headers))
+			if response.Headers["Content-Type"] != msgpackContentType || !response.IsBase64Encoded {
+				t.Errorf("response was not msgpack: %+v", response)
+			}
+		})
+	}
+}
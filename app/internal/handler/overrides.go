@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"weather-lambda/internal/db"
+)
+
+// unitOverrideValues maps each overridable field to the unit names it
+// accepts and the unit system each one means. Temperature and the summary's
+// wind speed are the unit-bearing fields in the response.
+var unitOverrideValues = map[string]map[string]string{
+	"temp": {"c": "metric", "f": "imperial"},
+	"wind": {"ms": "metric", "mph": "imperial"},
+}
+
+// parseUnitOverrides reads a unitOverrides parameter such as "temp=f,wind=mph".
+func parseUnitOverrides(value string) (map[string]string, error) {
+	overrides := map[string]string{}
+	for _, spec := range strings.Split(value, ",") {
+		field, unit, ok := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), "=")
+		if !ok {
+			return nil, fmt.Errorf("unit override %q must be field=unit", spec)
+		}
+		units, known := unitOverrideValues[field]
+		if !known {
+			return nil, fmt.Errorf("unit override for unsupported field %q", field)
+		}
+		system, valid := units[unit]
+		if !valid {
+			return nil, fmt.Errorf("unsupported unit %q for %s", unit, field)
+		}
+		overrides[field] = system
+	}
+	return overrides, nil
+}
+
+// temperatureUnits applies a temp override before the usual unit resolution.
+func temperatureUnits(data db.WeatherData, opts RequestOptions) string {
+	if units, ok := opts.UnitOverrides["temp"]; ok {
+		return units
+	}
+	return resolveUnits(data, opts)
+}
+
+// windUnits applies a wind override before the usual unit resolution.
+func windUnits(data db.WeatherData, opts RequestOptions) string {
+	if units, ok := opts.UnitOverrides["wind"]; ok {
+		return units
+	}
+	return resolveUnits(data, opts)
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"weather-lambda/internal/db"
+)
+
+func TestParseUnitOverrides(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"temp=f", map[string]string{"temp": "imperial"}, false},
+		{" TEMP=C ", map[string]string{"temp": "metric"}, false},
+		{"temp", nil, true},
+		{"temp=f,wind=mph", map[string]string{"temp": "imperial", "wind": "imperial"}, false},
+		{"wind=MS", map[string]string{"wind": "metric"}, false},
+		{"wind=kph", nil, true},
+		{"temp=k", nil, true},
+		{"temp=f,pressure=hpa", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseUnitOverrides(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("overrides = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemperatureUnitsOverride(t *testing.T) {
+	data := db.WeatherData{Location: &db.Location{Name: "Austin, Texas, United States"}}
+	tests := []struct {
+		name string
+		opts RequestOptions
+		want string
+	}{
+		{"inferred without an override", RequestOptions{}, "imperial"},
+		{"override beats inferred units", RequestOptions{UnitOverrides: map[string]string{"temp": "metric"}}, "metric"},
+		{"override beats explicit units", RequestOptions{Units: "metric", UnitOverrides: map[string]string{"temp": "imperial"}}, "imperial"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := temperatureUnits(data, tt.opts); got != tt.want {
+				t.Errorf("temperatureUnits = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindUnitsOverride(t *testing.T) {
+	data := db.WeatherData{Location: &db.Location{Name: "London, England, United Kingdom"}}
+	tests := []struct {
+		name string
+		opts RequestOptions
+		want string
+	}{
+		{"inferred without an override", RequestOptions{}, "metric"},
+		{"override beats inferred units", RequestOptions{UnitOverrides: map[string]string{"wind": "imperial"}}, "imperial"},
+		{"override beats explicit units", RequestOptions{Units: "imperial", UnitOverrides: map[string]string{"wind": "metric"}}, "metric"},
+		{"temp override leaves wind alone", RequestOptions{UnitOverrides: map[string]string{"temp": "imperial"}}, "metric"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := windUnits(data, tt.opts); got != tt.want {
+				t.Errorf("windUnits = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitOverridesRequest(t *testing.T) {
+	setupHandler(t)
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		return jsonResponse(200, realtimeBody(20, 50)), nil
+	})
+	city := uniqueCity(t)
+
+	response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city, "units": "metric", "unitOverrides": "temp=f"}, nil))
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d; body %s", response.StatusCode, response.Body)
+	}
+	if got := decodeReading(t, response).Temperature; math.Abs(got-68) > 0.01 {
+		t.Errorf("temperature = %v, want 68 °F", got)
+	}
+
+	response, _ = HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city, "unitOverrides": "pressure=hpa"}, nil))
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("unsupported field status = %d, want 400", response.StatusCode)
+	}
+}
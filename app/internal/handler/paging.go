@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"weather-lambda/internal/weather"
+)
+
+const (
+	defaultForecastLimit = 48
+	maxForecastLimit     = 168
+)
+
+// forecastPage selects a window of intervals from every returned timeline.
+type forecastPage struct {
+	limit  int
+	offset int
+}
+
+func parseForecastPage(params map[string]string) (forecastPage, error) {
+	page := forecastPage{limit: defaultForecastLimit}
+
+	if value := params["limit"]; value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxForecastLimit {
+			return forecastPage{}, fmt.Errorf("limit must be between 1 and %d", maxForecastLimit)
+		}
+		page.limit = limit
+	}
+
+	if value := params["offset"]; value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return forecastPage{}, fmt.Errorf("offset must be a non-negative integer")
+		}
+		page.offset = offset
+	}
+
+	return page, nil
+}
+
+// apply selects the page from every timeline. When any timeline has
+// intervals past the page, Next links to the following page: the request's
+// query with offset advanced by limit.
+func (p forecastPage) apply(response ForecastResponse, params map[string]string) ForecastResponse {
+	paged := ForecastResponse{
+		City:        response.City,
+		Timelines:   make(map[string][]weather.ForecastInterval, len(response.Timelines)),
+		Unavailable: response.Unavailable,
+	}
+	more := false
+	for timestep, intervals := range response.Timelines {
+		start := min(p.offset, len(intervals))
+		end := min(start+p.limit, len(intervals))
+		paged.Timelines[timestep] = intervals[start:end]
+		more = more || end < len(intervals)
+	}
+	if more {
+		paged.Next = p.nextLink(params)
+	}
+	return paged
+}
+
+func (p forecastPage) nextLink(params map[string]string) string {
+	query := url.Values{}
+	for name, value := range params {
+		query.Set(name, value)
+	}
+	query.Set("limit", strconv.Itoa(p.limit))
+	query.Set("offset", strconv.Itoa(p.offset+p.limit))
+	return "?" + query.Encode()
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"weather-lambda/internal/weather"
+)
+
+func TestParseForecastPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]string
+		want    forecastPage
+		wantErr bool
+	}{
+		{"defaults", nil, forecastPage{limit: defaultForecastLimit}, false},
+		{"limit and offset", map[string]string{"limit": "10", "offset": "20"}, forecastPage{limit: 10, offset: 20}, false},
+		{"largest limit", map[string]string{"limit": "168"}, forecastPage{limit: maxForecastLimit}, false},
+		{"zero limit", map[string]string{"limit": "0"}, forecastPage{}, true},
+		{"limit over the cap", map[string]string{"limit": "169"}, forecastPage{}, true},
+		{"limit not a number", map[string]string{"limit": "ten"}, forecastPage{}, true},
+		{"negative offset", map[string]string{"offset": "-1"}, forecastPage{}, true},
+		{"offset not a number", map[string]string{"offset": "1.5"}, forecastPage{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseForecastPage(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseForecastPage = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForecastPageApply(t *testing.T) {
+	response := ForecastResponse{
+		City: "Toronto",
+		Timelines: map[string][]weather.ForecastInterval{
+			"1h": forecastIntervals(10),
+			"1d": forecastIntervals(3),
+		},
+	}
+	params := map[string]string{"action": "forecast", "city": "Toronto", "timesteps": "1h,1d"}
+
+	tests := []struct {
+		name       string
+		page       forecastPage
+		wantHourly int
+		wantDaily  int
+		wantFirst  string
+		wantNext   string
+	}{
+		{"first page", forecastPage{limit: 4}, 4, 3, hourTime(0), "4"},
+		{"middle page", forecastPage{limit: 4, offset: 4}, 4, 0, hourTime(4), "8"},
+		{"last page", forecastPage{limit: 4, offset: 8}, 2, 0, hourTime(8), ""},
+		{"page ending exactly at the end", forecastPage{limit: 5, offset: 5}, 5, 0, hourTime(5), ""},
+		{"offset past the end", forecastPage{limit: 4, offset: 50}, 0, 0, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paged := tt.page.apply(response, params)
+			hourly, daily := paged.Timelines["1h"], paged.Timelines["1d"]
+			if len(hourly) != tt.wantHourly || len(daily) != tt.wantDaily {
+				t.Fatalf("got %d hourly and %d daily, want %d and %d", len(hourly), len(daily), tt.wantHourly, tt.wantDaily)
+			}
+			if tt.wantFirst != "" && hourly[0].Time != tt.wantFirst {
+				t.Errorf("first hourly interval = %s, want %s", hourly[0].Time, tt.wantFirst)
+			}
+
+			if tt.wantNext == "" {
+				if paged.Next != "" {
+					t.Errorf("Next = %q, want none on the last page", paged.Next)
+				}
+				return
+			}
+			next, err := url.ParseQuery(strings.TrimPrefix(paged.Next, "?"))
+			if err != nil {
+				t.Fatalf("Next %q is not a query string: %v", paged.Next, err)
+			}
+			if next.Get("offset") != tt.wantNext || next.Get("limit") != fmt.Sprint(tt.page.limit) {
+				t.Errorf("Next = %q, want offset=%s limit=%d", paged.Next, tt.wantNext, tt.page.limit)
+			}
+			for name, value := range params {
+				if next.Get(name) != value {
+					t.Errorf("Next %q lost %s=%s", paged.Next, name, value)
+				}
+			}
+		})
+	}
+
+	// The cached response is left whole for other pages
+	if len(response.Timelines["1h"]) != 10 {
+		t.Errorf("apply modified the response it paged")
+	}
+}
+
+func TestForecastPagingRequest(t *testing.T) {
+	setupHandler(t)
+	var body strings.Builder
+	body.WriteString(`{"timelines":{"hourly":[`)
+	for i := 0; i < 5; i++ {
+		if i > 0 {
+			body.WriteString(",")
+		}
+		fmt.Fprintf(&body, `{"time":%q,"values":{"temperature":%d}}`, hourTime(i), i)
+	}
+	body.WriteString(`]},"location":{"lat":1,"lon":2,"name":"Test"}}`)
+	calls := 0
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponse(200, body.String()), nil
+	})
+
+	params := map[string]string{"action": "forecast", "city": uniqueCity(t), "limit": "2"}
+	var times []string
+	for page := 0; page < 5; page++ {
+		response, _ := HandleRequest(context.Background(), weatherRequest(params, nil))
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d; body %s", response.StatusCode, response.Body)
+		}
+		var forecast ForecastResponse
+		if err := json.Unmarshal([]byte(response.Body), &forecast); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		for _, interval := range forecast.Timelines["1h"] {
+			times = append(times, interval.Time)
+		}
+		if forecast.Next == "" {
+			break
+		}
+
+		// Follow the link to the next page
+		next, err := url.ParseQuery(strings.TrimPrefix(forecast.Next, "?"))
+		if err != nil {
+			t.Fatalf("Next %q is not a query string: %v", forecast.Next, err)
+		}
+		params = map[string]string{}
+		for name := range next {
+			params[name] = next.Get(name)
+		}
+	}
+
+	if len(times) != 5 || times[0] != hourTime(0) || times[4] != hourTime(4) {
+		t.Errorf("paged through %v, want all 5 intervals in order", times)
+	}
+	if calls != 1 {
+		t.Errorf("upstream calls = %d, want 1 with later pages served from the cache", calls)
+	}
+}
+
+func forecastIntervals(n int) []weather.ForecastInterval {
+	intervals := make([]weather.ForecastInterval, n)
+	for i := range intervals {
+		intervals[i] = weather.ForecastInterval{Time: hourTime(i)}
+	}
+	return intervals
+}
+
+func hourTime(i int) string {
+	return fmt.Sprintf("2024-03-01T%02d:00:00Z", i)
+}
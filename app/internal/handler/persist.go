@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/log"
+)
+
+// persist writes a fresh reading to the store and then the cache, so the
+// cache never holds a reading the table is missing. A failed store write
+// fails the request unless PERSIST_MODE=best-effort, in which case it is
+// logged and the reading is still cached and returned. The S3 snapshot, when
+// enabled, is refreshed last in the background. Cities requested fewer than
+// DB_MIN_REQUESTS_BEFORE_PERSIST times, or already written by this container
+// within DB_DEDUP_WINDOW_SECONDS, are cached without the store write. A
+// stored reading always carries today's range forward.
+//
+// Unless WRITE_COORDINATION=none, writes for the same cache key are
+// serialized, and a reading older than one persisted by a request still
+// holding or waiting on the key is dropped, so the newest reading wins.
+func persist(ctx context.Context, cacheKey string, data db.WeatherData) error {
+	if writeCoordination() == "none" {
+		return write(ctx, cacheKey, data)
+	}
+
+	entry, unlock := writeLocks.lock(cacheKey)
+	defer unlock()
+	if entry.written != "" && data.Time < entry.written {
+		log.InfoContext(ctx, fmt.Sprintf("Skipping write of older reading for city: %s", data.City))
+		notePath(ctx, "superseded")
+		return nil
+	}
+	if err := write(ctx, cacheKey, data); err != nil {
+		return err
+	}
+	entry.written = data.Time
+	return nil
+}
+
+func write(ctx context.Context, cacheKey string, data db.WeatherData) error {
+	if !popular(data.City) {
+		log.InfoContext(ctx, fmt.Sprintf("Caching without persisting rarely requested city: %s", data.City))
+		notePath(ctx, "persist-unpopular")
+	} else if recentlyPersisted(data.City) {
+		log.InfoContext(ctx, fmt.Sprintf("Skipping write of recently persisted city: %s", data.City))
+		notePath(ctx, "persist-deduped")
+	} else if err := store.Save(ctx, withDailyRange(ctx, data)); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error saving weather data to DynamoDB: %v", err))
+		notePath(ctx, "persist-failed")
+		if os.Getenv("PERSIST_MODE") != "best-effort" {
+			return fmt.Errorf("%w: %w", ErrPersistence, err)
+		}
+	} else {
+		markPersisted(data.City)
+		notePath(ctx, "persisted")
+	}
+
+	cache.SetCache(cacheKey, data)
+	notePath(ctx, "cached")
+	saveSnapshot(ctx, data)
+	return nil
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"weather-lambda/internal/db"
+)
+
+// failingStore refuses every write.
+type failingStore struct {
+	db.MemoryStore
+}
+
+func (*failingStore) Save(context.Context, db.WeatherData) error {
+	return errors.New("table unavailable")
+}
+
+func TestPersistOrdering(t *testing.T) {
+	tests := []struct {
+		name        string
+		failSave    bool
+		persistMode string
+		wantStatus  int
+		wantCached  bool
+	}{
+		{"store and cache", false, "", http.StatusOK, true},
+		{"store fails", true, "", http.StatusInternalServerError, false},
+		{"store fails best-effort", true, "best-effort", http.StatusOK, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t)
+			t.Setenv("PERSIST_MODE", tt.persistMode)
+			if tt.failSave {
+				store = &failingStore{}
+			}
+			calls := 0
+			stubUpstream(t, func(*http.Request) (*http.Response, error) {
+				calls++
+				return jsonResponse(200, realtimeBody(20, 50)), nil
+			})
+
+			request := weatherRequest(map[string]string{"city": uniqueCity(t)}, nil)
+			response, _ := HandleRequest(context.Background(), request)
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+
+			// A second request is served from the cache only if the first cached its reading
+			HandleRequest(context.Background(), request)
+			if cached := calls == 1; cached != tt.wantCached {
+				t.Errorf("cached = %v after %d upstream calls, want %v", cached, calls, tt.wantCached)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"weather-lambda/internal/cache"
+)
+
+// requestCountWindow is how long a city's request count is kept; a city
+// must reach the threshold within it to be persisted. The window is fixed
+// from the first request, so a steady trickle of requests cannot keep an
+// old count alive.
+const requestCountWindow = 24 * time.Hour
+
+// requestCount is a city's request count for the window ending at windowEnd
+type requestCount struct {
+	count     int
+	windowEnd time.Time
+}
+
+// requestCounts serializes the read-modify-write of the cached counters
+var requestCounts sync.Mutex
+
+func requestCountKey(city string) string {
+	return cache.NamespacedKey("requests", city)
+}
+
+// minRequestsBeforePersist reads DB_MIN_REQUESTS_BEFORE_PERSIST. Below 2
+// every reading is persisted, as before.
+func minRequestsBeforePersist() int {
+	threshold, err := strconv.Atoi(os.Getenv("DB_MIN_REQUESTS_BEFORE_PERSIST"))
+	if err != nil || threshold < 2 {
+		return 0
+	}
+	return threshold
+}
+
+// countRequest notes a request for a city. Counts live in the cache, so they
+// are per container and reset on cold start or eviction.
+func countRequest(city string) {
+	if minRequestsBeforePersist() == 0 {
+		return
+	}
+
+	requestCounts.Lock()
+	defer requestCounts.Unlock()
+	counter, found := currentCount(city)
+	if !found {
+		counter = requestCount{windowEnd: time.Now().Add(requestCountWindow)}
+	}
+	counter.count++
+	cache.SetCacheFor(requestCountKey(city), counter, time.Until(counter.windowEnd))
+}
+
+// popular reports whether a city has been requested often enough to be
+// written to the store. Less popular cities are served from the cache only.
+func popular(city string) bool {
+	threshold := minRequestsBeforePersist()
+	if threshold == 0 {
+		return true
+	}
+
+	requestCounts.Lock()
+	defer requestCounts.Unlock()
+	counter, _ := currentCount(city)
+	return counter.count >= threshold
+}
+
+// currentCount reads a city's counter without touching the cache hit and
+// miss counts. The caller must hold requestCounts.
+func currentCount(city string) (requestCount, bool) {
+	cached, found := cache.Lookup(requestCountKey(city))
+	counter, ok := cached.(requestCount)
+	if !found || !ok || !time.Now().Before(counter.windowEnd) {
+		return requestCount{}, false
+	}
+	return counter, true
+}
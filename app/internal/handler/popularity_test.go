@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"weather-lambda/internal/cache"
+)
+
+func TestPopularAfterThreshold(t *testing.T) {
+	setupHandler(t)
+	t.Setenv("DB_MIN_REQUESTS_BEFORE_PERSIST", "3")
+	city := uniqueCity(t)
+
+	for i := 1; i <= 3; i++ {
+		countRequest(city)
+		if got, want := popular(city), i >= 3; got != want {
+			t.Errorf("after %d requests popular = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestPopularWithoutThreshold(t *testing.T) {
+	setupHandler(t)
+	t.Setenv("DB_MIN_REQUESTS_BEFORE_PERSIST", "")
+	if !popular(uniqueCity(t)) {
+		t.Errorf("an unrequested city is not popular with no threshold set")
+	}
+}
+
+func TestRequestCountWindowIsFixed(t *testing.T) {
+	setupHandler(t)
+	t.Setenv("DB_MIN_REQUESTS_BEFORE_PERSIST", "2")
+	city := uniqueCity(t)
+	windowEnd := time.Now().Add(time.Hour)
+	cache.SetCacheFor(requestCountKey(city), requestCount{count: 1, windowEnd: windowEnd}, time.Hour)
+
+	countRequest(city)
+	counter, found := currentCount(city)
+	if !found || counter.count != 2 {
+		t.Fatalf("count = %d (found %v), want 2", counter.count, found)
+	}
+	if !counter.windowEnd.Equal(windowEnd) {
+		t.Errorf("window end moved from %v to %v", windowEnd, counter.windowEnd)
+	}
+}
+
+func TestRequestCountWindowExpires(t *testing.T) {
+	setupHandler(t)
+	t.Setenv("DB_MIN_REQUESTS_BEFORE_PERSIST", "2")
+	city := uniqueCity(t)
+	cache.SetCacheFor(requestCountKey(city), requestCount{count: 5, windowEnd: time.Now().Add(-time.Second)}, time.Hour)
+
+	if popular(city) {
+		t.Errorf("a count from an ended window made the city popular")
+	}
+	countRequest(city)
+	counter, _ := currentCount(city)
+	if counter.count != 1 || !counter.windowEnd.After(time.Now().Add(requestCountWindow-time.Minute)) {
+		t.Errorf("counter = %+v, want a new window starting at 1", counter)
+	}
+}
+
+func TestRequestCountsLeaveHitRatioAlone(t *testing.T) {
+	setupHandler(t)
+	t.Setenv("DB_MIN_REQUESTS_BEFORE_PERSIST", "2")
+	city := uniqueCity(t)
+
+	before := cache.CurrentStats()
+	countRequest(city)
+	popular(city)
+	countRequest(city)
+	popular(city)
+	after := cache.CurrentStats()
+	if after.Hits != before.Hits || after.Misses != before.Misses {
+		t.Errorf("request counting changed hits %d->%d, misses %d->%d", before.Hits, after.Hits, before.Misses, after.Misses)
+	}
+}
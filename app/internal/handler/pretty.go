@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func isPrettyRequest(request events.APIGatewayProxyRequest) bool {
+	return request.QueryStringParameters["pretty"] == "true"
+}
+
+// isJSONResponse reports a JSON body, including +json types such as GeoJSON.
+// Bodies without a Content-Type are built by buildResponse and are JSON.
+func isJSONResponse(response events.APIGatewayProxyResponse) bool {
+	contentType := response.Headers["Content-Type"]
+	return contentType == "" || strings.HasPrefix(contentType, "application/json") || strings.Contains(contentType, "+json")
+}
+
+// prettyPrint indents a JSON response body for people reading it in a
+// browser or terminal. Other content types are left alone.
+func prettyPrint(response events.APIGatewayProxyResponse) events.APIGatewayProxyResponse {
+	if !isJSONResponse(response) {
+		return response
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(response.Body), "", "  "); err != nil {
+		return response
+	}
+	response.Body = indented.String()
+	return response
+}
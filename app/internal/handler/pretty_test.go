@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestPrettyPrint(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{"JSON without a content type", "", `{"a":1,"b":[2]}`, "{\n  \"a\": 1,\n  \"b\": [\n    2\n  ]\n}"},
+		{"application/json", "application/json; charset=utf-8", `{"a":1}`, "{\n  \"a\": 1\n}"},
+		{"+json type", "application/geo+json", `{"a":1}`, "{\n  \"a\": 1\n}"},
+		{"plain text left alone", "text/plain", `{"a":1}`, `{"a":1}`},
+		{"invalid JSON left alone", "", `{"a":`, `{"a":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := events.APIGatewayProxyResponse{Body: tt.body}
+			if tt.contentType != "" {
+				response.Headers = map[string]string{"Content-Type": tt.contentType}
+			}
+			if got := prettyPrint(response).Body; got != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrettyRequest(t *testing.T) {
+	setupHandler(t)
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		return jsonResponse(200, realtimeBody(20, 50)), nil
+	})
+	city := uniqueCity(t)
+
+	compact, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city}, nil))
+	pretty, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city, "pretty": "true"}, nil))
+	if strings.Contains(compact.Body, "\n") {
+		t.Errorf("body without pretty=true is indented: %q", compact.Body)
+	}
+	if !strings.Contains(pretty.Body, "\n  \"City\"") {
+		t.Errorf("body with pretty=true is not indented: %q", pretty.Body)
+	}
+	if got := decodeReading(t, pretty); got.City != decodeReading(t, compact).City {
+		t.Errorf("pretty body decodes to a different reading")
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"weather-lambda/internal/log"
+	"weather-lambda/internal/weather"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func isRawRequest(request events.APIGatewayProxyRequest) bool {
+	return request.QueryStringParameters["raw"] == "true"
+}
+
+// handleRaw returns the upstream body untouched for debugging. It skips the
+// cache and persistence so the body always reflects a live fetch.
+func handleRaw(ctx context.Context, request events.APIGatewayProxyRequest, city string) (events.APIGatewayProxyResponse, error) {
+	if !isAuthorized(request) {
+		log.ErrorContext(ctx, "Raw mode requested without a valid API key")
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: raw mode requires an API key", ErrForbidden)
+	}
+
+	weatherResponse, err := weather.FetchWeatherByCity(ctx, city)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error fetching weather data: %v", err))
+		countUpstreamError(err)
+		return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: %w", ErrUpstream, err)
+	}
+
+	log.InfoContext(ctx, fmt.Sprintf("Returning raw upstream data for city: %s", city))
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(weatherResponse.Raw),
+	}, nil
+}
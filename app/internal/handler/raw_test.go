@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRawRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{"authorized", map[string]string{"X-Api-Key": "admin-key"}, http.StatusOK},
+		{"wrong key", map[string]string{"X-Api-Key": "guess"}, http.StatusForbidden},
+		{"no key", nil, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t)
+			t.Setenv("ADMIN_API_KEY", "admin-key")
+
+			// The upstream echoes the request URL, API key included
+			var upstreamBody string
+			stubUpstream(t, func(r *http.Request) (*http.Response, error) {
+				upstreamBody = `{"data":{"time":"2024-03-01T12:00:00Z","values":{"temperature":20.5,"humidity":50,"extra":1}},` +
+					`"location":{"name":"Test"},"request":"` + r.URL.String() + `"}`
+				return jsonResponse(200, upstreamBody), nil
+			})
+
+			params := map[string]string{"city": uniqueCity(t), "raw": "true"}
+			response, _ := HandleRequest(context.Background(), weatherRequest(params, tt.headers))
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body %s", response.StatusCode, tt.wantStatus, response.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			if strings.Contains(response.Body, "test-key") {
+				t.Errorf("raw body leaks the API key: %s", response.Body)
+			}
+			if want := strings.ReplaceAll(upstreamBody, "test-key", "REDACTED"); response.Body != want {
+				t.Errorf("raw body =\n%s\nwant the upstream body with the key redacted\n%s", response.Body, want)
+			}
+			if !strings.Contains(response.Body, "apikey=REDACTED") {
+				t.Errorf("raw body %s does not show the redacted key", response.Body)
+			}
+		})
+	}
+}
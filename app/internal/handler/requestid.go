@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+var defaultRequestIDHeaders = []string{"X-Request-ID", "X-Amzn-Trace-Id", "traceparent"}
+
+// requestID returns the first ID found in the configured headers, checked in
+// REQUEST_ID_HEADERS order, or a new random ID when none is present.
+func requestID(headers map[string]string) string {
+	for _, name := range requestIDHeaders() {
+		if id := headerValue(headers, name); id != "" {
+			return id
+		}
+	}
+	return newRequestID()
+}
+
+func requestIDHeaders() []string {
+	value := os.Getenv("REQUEST_ID_HEADERS")
+	if value == "" {
+		return defaultRequestIDHeaders
+	}
+
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		headers map[string]string
+		want    string
+	}{
+		{"X-Request-ID first", "", map[string]string{"X-Request-ID": "req-1", "X-Amzn-Trace-Id": "trace-1"}, "req-1"},
+		{"falls back to the trace header", "", map[string]string{"X-Amzn-Trace-Id": "trace-1", "traceparent": "parent-1"}, "trace-1"},
+		{"header names are case-insensitive", "", map[string]string{"x-request-id": "req-2"}, "req-2"},
+		{"configured order wins", "traceparent,X-Request-ID", map[string]string{"X-Request-ID": "req-1", "traceparent": "parent-1"}, "parent-1"},
+		{"configured list skips empty names", " , X-Correlation-ID", map[string]string{"X-Correlation-ID": "corr-1", "X-Request-ID": "req-1"}, "corr-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("REQUEST_ID_HEADERS", tt.env)
+			if got := requestID(tt.headers); got != tt.want {
+				t.Errorf("requestID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	t.Setenv("REQUEST_ID_HEADERS", "")
+	valid := regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+	first := requestID(map[string]string{"Accept": "application/json"})
+	second := requestID(nil)
+	if !valid.MatchString(first) || !valid.MatchString(second) {
+		t.Fatalf("generated IDs = %q, %q, want 32 hex characters", first, second)
+	}
+	if first == second {
+		t.Errorf("generated the same ID twice: %q", first)
+	}
+
+	// A header outside the configured list is ignored
+	t.Setenv("REQUEST_ID_HEADERS", "X-Correlation-ID")
+	if got := requestID(map[string]string{"X-Request-ID": "req-1"}); got == "req-1" || !valid.MatchString(got) {
+		t.Errorf("requestID = %q, want a generated ID", got)
+	}
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	_ "embed"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+//go:embed openapi.json
+var openAPIDocument string
+
+func isSchemaRequest(request events.APIGatewayProxyRequest) bool {
+	return request.QueryStringParameters["action"] == "schema" || strings.HasSuffix(request.Path, "/openapi.json")
+}
+
+func buildSchemaResponse() events.APIGatewayProxyResponse {
+	return events.APIGatewayProxyResponse{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       openAPIDocument,
+	}
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func TestSchemaRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		request events.APIGatewayProxyRequest
+	}{
+		{"action", events.APIGatewayProxyRequest{QueryStringParameters: map[string]string{"action": "schema"}}},
+		{"path", events.APIGatewayProxyRequest{Path: "/openapi.json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := HandleRequest(context.Background(), Event{APIGatewayProxyRequest: tt.request})
+			if err != nil {
+				t.Fatalf("HandleRequest: %v", err)
+			}
+			if response.StatusCode != 200 {
+				t.Fatalf("status = %d, want 200", response.StatusCode)
+			}
+			if got := response.Headers["Content-Type"]; got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+
+			var document struct {
+				OpenAPI string                     `json:"openapi"`
+				Paths   map[string]json.RawMessage `json:"paths"`
+			}
+			if err := json.Unmarshal([]byte(response.Body), &document); err != nil {
+				t.Fatalf("schema is not valid JSON: %v", err)
+			}
+			if document.OpenAPI == "" || document.Paths["/weather"] == nil {
+				t.Errorf("schema is missing the openapi version or /weather path")
+			}
+		})
+	}
+}
+
+func TestSchemaListsQueryParameters(t *testing.T) {
+	var document struct {
+		Paths map[string]map[string]struct {
+			Parameters []struct {
+				Name string `json:"name"`
+				In   string `json:"in"`
+			} `json:"parameters"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal([]byte(openAPIDocument), &document); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	listed := map[string]bool{}
+	for _, parameter := range document.Paths["/weather"]["get"].Parameters {
+		if parameter.In == "query" {
+			listed[parameter.Name] = true
+		}
+	}
+
+	// Every query parameter the handler reads
+	for _, name := range []string{
+		"city", "airport", "bbox", "grid", "action", "timesteps", "limit", "offset",
+		"units", "precision", "fields", "unitOverrides", "format", "pretty", "summary",
+		"includeAirQuality", "includeMoonPhase", "includeTrend", "includeDelta", "includeDailyRange",
+		"disambiguate", "apiVersion", "raw", "debug", "stream", "interval",
+	} {
+		if !listed[name] {
+			t.Errorf("schema does not list the %s query parameter", name)
+		}
+	}
+}
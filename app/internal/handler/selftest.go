@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/log"
+	"weather-lambda/internal/weather"
+)
+
+const (
+	defaultSelfTestCity = "Toronto"
+
+	// selfTestTimeout bounds the whole self-test, which holds up the cold
+	// start it runs in
+	selfTestTimeout = 5 * time.Second
+
+	// selfTestPrefix keeps self-test rows apart from real cities
+	selfTestPrefix = "SELFTEST#"
+
+	// selfTestRowTTL is how long a self-test row is kept before the table's
+	// TTL removes it
+	selfTestRowTTL = time.Hour
+)
+
+// SelfTest runs once at cold start when the self-test feature is on. It
+// fetches SELF_TEST_CITY from the upstream, writes the reading to the store
+// under a SELFTEST# key that expires after an hour and reads it back, then
+// round-trips it through the cache under the selftest namespace. Each step
+// is logged with a pass/fail summary; the returned error joins every
+// failure, for cmd/main.go to refuse to start on when SELF_TEST_STRICT=true.
+func SelfTest(ctx context.Context) error {
+	if !features.Enabled("self-test") {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+	defer cancel()
+
+	city := os.Getenv("SELF_TEST_CITY")
+	if city == "" {
+		city = defaultSelfTestCity
+	}
+
+	var failures []error
+	step := func(name string, err error) bool {
+		if err != nil {
+			log.ErrorContext(ctx, fmt.Sprintf("Self-test %s: FAIL: %v", name, err))
+			failures = append(failures, fmt.Errorf("%s: %w", name, err))
+			return false
+		}
+		log.InfoContext(ctx, fmt.Sprintf("Self-test %s: PASS", name))
+		return true
+	}
+
+	weatherResponse, err := weather.FetchWeatherByCity(ctx, city)
+	if step("upstream", err) {
+		data := db.WeatherData{
+			City:        selfTestPrefix + city,
+			Temperature: weatherResponse.Data.Values.Temperature,
+			Humidity:    weatherResponse.Data.Values.Humidity,
+			Time:        weatherResponse.Data.Time,
+			Provider:    weather.Provider,
+			ExpiresAt:   time.Now().Add(selfTestRowTTL).Unix(),
+		}
+		if !db.Enabled() {
+			log.InfoContext(ctx, "Self-test store: skipped with PERSISTENCE=none")
+		} else if step("store write", store.Save(ctx, data)) {
+			step("store read", readBack(ctx, data.City))
+		}
+		step("cache", cacheRoundTrip(data))
+	}
+
+	if len(failures) > 0 {
+		log.ErrorContext(ctx, fmt.Sprintf("Self-test failed: %d step(s) failed", len(failures)))
+		return errors.Join(failures...)
+	}
+	log.InfoContext(ctx, "Self-test passed")
+	return nil
+}
+
+func readBack(ctx context.Context, city string) error {
+	_, found, err := store.Get(ctx, city)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("written reading not found")
+	}
+	return nil
+}
+
+func cacheRoundTrip(data db.WeatherData) error {
+	key := cache.NamespacedKey("selftest", data.City)
+	cache.SetCacheFor(key, data, time.Minute)
+	cached, found := cache.GetCache(key)
+	if reading, ok := cached.(db.WeatherData); !found || !ok || reading.City != data.City {
+		return errors.New("cached reading not returned")
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSelfTest(t *testing.T) {
+	tests := []struct {
+		name           string
+		selfTest       bool
+		persistence    string
+		upstreamStatus int
+		wantErr        bool
+		wantCalls      int
+		wantRow        bool
+	}{
+		{name: "off", upstreamStatus: 200},
+		{name: "passes", selfTest: true, upstreamStatus: 200, wantCalls: 1, wantRow: true},
+		{name: "store skipped", selfTest: true, persistence: "none", upstreamStatus: 200, wantCalls: 1},
+		{name: "upstream fails", selfTest: true, upstreamStatus: 401, wantErr: true, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var enabled []string
+			if tt.selfTest {
+				enabled = append(enabled, "self-test")
+			}
+			memory := setupHandler(t, enabled...)
+			city := uniqueCity(t)
+			t.Setenv("SELF_TEST_CITY", city)
+			t.Setenv("PERSISTENCE", tt.persistence)
+			calls := 0
+			stubUpstream(t, func(*http.Request) (*http.Response, error) {
+				calls++
+				if tt.upstreamStatus != 200 {
+					return jsonResponse(tt.upstreamStatus, `{}`), nil
+				}
+				return jsonResponse(200, realtimeBody(20, 50)), nil
+			})
+
+			err := SelfTest(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SelfTest = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("upstream calls = %d, want %d", calls, tt.wantCalls)
+			}
+
+			row, found, _ := memory.Get(context.Background(), selfTestPrefix+city)
+			if found != tt.wantRow {
+				t.Fatalf("self-test row stored = %v, want %v", found, tt.wantRow)
+			}
+			if found {
+				expires := time.Unix(row.ExpiresAt, 0)
+				if !expires.After(time.Now()) || expires.After(time.Now().Add(selfTestRowTTL)) {
+					t.Errorf("row expires at %v, want within %v from now", expires, selfTestRowTTL)
+				}
+			}
+		})
+	}
+}
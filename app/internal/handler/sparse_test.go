@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"weather-lambda/internal/cache"
+)
+
+func TestSparseEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		request    events.APIGatewayProxyRequest
+		wantStatus int
+	}{
+		{"empty event", events.APIGatewayProxyRequest{}, http.StatusBadRequest},
+		{"nil query", events.APIGatewayProxyRequest{Headers: map[string]string{"Accept": "application/json"}}, http.StatusBadRequest},
+		{"nil headers", events.APIGatewayProxyRequest{QueryStringParameters: map[string]string{"city": "sparse-headers-town"}}, http.StatusOK},
+		{"nil stage variables", events.APIGatewayProxyRequest{QueryStringParameters: map[string]string{"city": "sparse-stage-town"}, Headers: map[string]string{}}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t)
+			stubUpstream(t, func(*http.Request) (*http.Response, error) {
+				return jsonResponse(200, realtimeBody(20, 50)), nil
+			})
+
+			response, err := HandleRequest(context.Background(), Event{APIGatewayProxyRequest: tt.request})
+			if err != nil {
+				t.Fatalf("HandleRequest: %v", err)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body %s", response.StatusCode, tt.wantStatus, response.Body)
+			}
+			if response.Headers[requestIDHeader] == "" {
+				t.Errorf("response has no %s header", requestIDHeader)
+			}
+		})
+	}
+}
+
+func TestWrongTypeInCacheIsAMiss(t *testing.T) {
+	setupHandler(t)
+	city := uniqueCity(t)
+	cache.SetCache(weatherCacheKey(city, RequestOptions{}), "not a reading")
+
+	calls := 0
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponse(200, realtimeBody(20, 50)), nil
+	})
+
+	response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city}, nil))
+	if response.StatusCode != http.StatusOK || calls != 1 {
+		t.Errorf("status = %d after %d upstream calls, want 200 after 1", response.StatusCode, calls)
+	}
+}
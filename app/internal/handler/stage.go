@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"os"
+
+	"weather-lambda/internal/db"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Stage variables let one function serve several API Gateway stages with
+// different config. When set, they take precedence over the env vars.
+const (
+	tableNameStageVar    = "tableName"
+	defaultUnitsStageVar = "defaultUnits"
+)
+
+func stageValue(request events.APIGatewayProxyRequest, stageVar string, envVar string) string {
+	if value := request.StageVariables[stageVar]; value != "" {
+		return value
+	}
+	return os.Getenv(envVar)
+}
+
+func withStageConfig(ctx context.Context, request events.APIGatewayProxyRequest) context.Context {
+	if table := request.StageVariables[tableNameStageVar]; table != "" {
+		ctx = db.WithTableName(ctx, table)
+	}
+	return ctx
+}
+
+func defaultUnits(request events.APIGatewayProxyRequest) string {
+	return stageValue(request, defaultUnitsStageVar, "DEFAULT_UNITS")
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"weather-lambda/internal/log"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	defaultStreamInterval    = 30 * time.Second
+	defaultStreamMinInterval = 10 * time.Second
+	defaultStreamMaxDuration = 5 * time.Minute
+)
+
+// HandleStream serves a Lambda Function URL whose invoke mode is
+// RESPONSE_STREAM, selected in cmd/main.go with LAMBDA_INVOKE_MODE=stream.
+// stream=true answers with server-sent events; any other request is handled
+// by HandleRequest and written in one piece.
+func HandleStream(ctx context.Context, urlRequest events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+	event := Event{APIGatewayProxyRequest: proxyRequest(urlRequest)}
+
+	if event.QueryStringParameters["stream"] != "true" {
+		response, _ := HandleRequest(ctx, event)
+		body := []byte(response.Body)
+		if response.IsBase64Encoded {
+			if decoded, err := base64.StdEncoding.DecodeString(response.Body); err == nil {
+				body = decoded
+			}
+		}
+		return &events.LambdaFunctionURLStreamingResponse{
+			StatusCode: response.StatusCode,
+			Headers:    response.Headers,
+			Body:       strings.NewReader(string(body)),
+		}, nil
+	}
+
+	interval, err := streamInterval(event.QueryStringParameters["interval"])
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Invalid stream interval: %v", err))
+		return &events.LambdaFunctionURLStreamingResponse{StatusCode: statusForError(err), Body: strings.NewReader("")}, nil
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		writer.CloseWithError(streamReadings(ctx, writer, event, ticker.C))
+	}()
+	return &events.LambdaFunctionURLStreamingResponse{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": "text/event-stream", "Cache-Control": "no-cache"},
+		Body:       reader,
+	}, nil
+}
+
+// streamReadings writes a reading as an SSE event now and on every tick until
+// the client disconnects, the invocation nears its deadline or
+// STREAM_MAX_DURATION_SECONDS passes. Readings come through HandleRequest,
+// so the cache still absorbs ticks that arrive before the entry expires.
+// Failed readings are sent as error events and the stream carries on.
+func streamReadings(ctx context.Context, w io.Writer, event Event, ticks <-chan time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, streamMaxDuration())
+	defer cancel()
+
+	for {
+		response, _ := HandleRequest(ctx, event)
+		name := "reading"
+		if response.StatusCode != 200 {
+			name = "error"
+		}
+		if _, err := io.WriteString(w, sseEvent(name, response.Body)); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticks:
+		}
+	}
+}
+
+// sseEvent formats one server-sent event, splitting multi-line data.
+func sseEvent(name string, data string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "event: %s\n", name)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// streamInterval parses the interval parameter as a duration such as "30s",
+// rejecting values below STREAM_MIN_INTERVAL_SECONDS to protect quota.
+func streamInterval(value string) (time.Duration, error) {
+	if value == "" {
+		return max(defaultStreamInterval, streamMinInterval()), nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: interval must be a duration such as 30s", ErrValidation)
+	}
+	if minimum := streamMinInterval(); interval < minimum {
+		return 0, fmt.Errorf("%w: interval must be at least %s", ErrValidation, minimum)
+	}
+	return interval, nil
+}
+
+func streamMinInterval() time.Duration {
+	return envSeconds("STREAM_MIN_INTERVAL_SECONDS", defaultStreamMinInterval)
+}
+
+func streamMaxDuration() time.Duration {
+	return envSeconds("STREAM_MAX_DURATION_SECONDS", defaultStreamMaxDuration)
+}
+
+func envSeconds(envVar string, fallback time.Duration) time.Duration {
+	seconds, err := strconv.Atoi(os.Getenv(envVar))
+	if err != nil || seconds <= 0 {
+		return fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// proxyRequest maps a Function URL request onto the API Gateway request the
+// rest of the handler works with.
+func proxyRequest(request events.LambdaFunctionURLRequest) events.APIGatewayProxyRequest {
+	proxy := events.APIGatewayProxyRequest{
+		HTTPMethod:            request.RequestContext.HTTP.Method,
+		Path:                  request.RawPath,
+		Headers:               request.Headers,
+		QueryStringParameters: request.QueryStringParameters,
+		Body:                  request.Body,
+		IsBase64Encoded:       request.IsBase64Encoded,
+	}
+	proxy.RequestContext.RequestID = request.RequestContext.RequestID
+	proxy.RequestContext.Identity.SourceIP = request.RequestContext.HTTP.SourceIP
+	return proxy
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// readEvent reads one server-sent event, up to its blank line.
+func readEvent(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	var event strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event: %v (so far %q)", err, event.String())
+		}
+		if line == "\n" {
+			return event.String()
+		}
+		event.WriteString(line)
+	}
+}
+
+func TestStreamReadingsEmitsAnEventPerTick(t *testing.T) {
+	setupHandler(t)
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		return jsonResponse(200, realtimeBody(21.5, 50)), nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reader, writer := io.Pipe()
+	ticks := make(chan time.Time)
+	done := make(chan error, 1)
+	go func() {
+		err := streamReadings(ctx, writer, weatherRequest(map[string]string{"city": uniqueCity(t)}, nil), ticks)
+		writer.CloseWithError(err)
+		done <- err
+	}()
+
+	events := bufio.NewReader(reader)
+	for i := 0; i < 3; i++ {
+		if i > 0 {
+			ticks <- time.Now()
+		}
+		event := readEvent(t, events)
+		if !strings.HasPrefix(event, "event: reading\ndata: {") || !strings.Contains(event, `"Temperature":21.5`) {
+			t.Errorf("event %d = %q, want a reading", i, event)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("streamReadings = %v after the client left", err)
+	}
+}
+
+func TestStreamReadingsSendsErrorEvents(t *testing.T) {
+	setupHandler(t)
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		return jsonResponse(404, `{}`), nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(streamReadings(ctx, writer, weatherRequest(map[string]string{"city": uniqueCity(t)}, nil), nil))
+	}()
+
+	if event := readEvent(t, bufio.NewReader(reader)); !strings.HasPrefix(event, "event: error\n") {
+		t.Errorf("event = %q, want an error event", event)
+	}
+}
+
+func TestStreamReadingsStopsAtMaxDuration(t *testing.T) {
+	setupHandler(t)
+	t.Setenv("STREAM_MAX_DURATION_SECONDS", "1")
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		return jsonResponse(200, realtimeBody(21.5, 50)), nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- streamReadings(context.Background(), io.Discard, weatherRequest(map[string]string{"city": uniqueCity(t)}, nil), nil)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("streamReadings = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream outlived STREAM_MAX_DURATION_SECONDS")
+	}
+}
+
+func TestSSEEvent(t *testing.T) {
+	tests := []struct {
+		name, data, want string
+	}{
+		{"single line", `{"a":1}`, "event: reading\ndata: {\"a\":1}\n\n"},
+		{"multi-line", "{\n  \"a\": 1\n}", "event: reading\ndata: {\ndata:   \"a\": 1\ndata: }\n\n"},
+		{"empty", "", "event: reading\ndata: \n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sseEvent("reading", tt.data); got != tt.want {
+				t.Errorf("sseEvent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamInterval(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		minimum string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "default", want: defaultStreamInterval},
+		{name: "default raised to the minimum", minimum: "60", want: time.Minute},
+		{name: "set", value: "45s", want: 45 * time.Second},
+		{name: "at the minimum", value: "10s", want: 10 * time.Second},
+		{name: "below the minimum", value: "5s", wantErr: true},
+		{name: "not a duration", value: "30", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("STREAM_MIN_INTERVAL_SECONDS", tt.minimum)
+			got, err := streamInterval(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrValidation) {
+					t.Errorf("err = %v, want ErrValidation", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("streamInterval(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleStreamRejectsShortInterval(t *testing.T) {
+	setupHandler(t)
+	response, err := HandleStream(context.Background(), events.LambdaFunctionURLRequest{
+		QueryStringParameters: map[string]string{"city": "Toronto", "stream": "true", "interval": "1s"},
+	})
+	if err != nil {
+		t.Fatalf("HandleStream: %v", err)
+	}
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", response.StatusCode)
+	}
+}
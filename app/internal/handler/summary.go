@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/weather"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// metersPerSecondToMph converts tomorrow.io's metric wind speed
+const metersPerSecondToMph = 2.236936
+
+// CurrentSummary is the condensed reading returned for summary=true.
+// FeelsLike and Wind are null when the reading did not include them, such as
+// one derived from the forecast.
+type CurrentSummary struct {
+	Temp      float64  `json:"temp"`
+	FeelsLike *float64 `json:"feelsLike"`
+	Condition string   `json:"condition"`
+	Precip    bool     `json:"precip"`
+	Wind      *float64 `json:"wind"`
+}
+
+func conditions(values weather.WeatherDataValues) *db.Conditions {
+	return &db.Conditions{
+		FeelsLike: values.TemperatureApparent,
+		Condition: weather.WeatherCodeLabel(values.WeatherCode),
+		Precipitation: values.RainIntensity > 0 || values.SnowIntensity > 0 ||
+			values.SleetIntensity > 0 || values.FreezingRainIntensity > 0,
+		WindSpeed: values.WindSpeed,
+	}
+}
+
+// buildSummaryResponse condenses a transformed reading, so the summary uses
+// the same units and precision as the full object would.
+func buildSummaryResponse(response *Response) (events.APIGatewayProxyResponse, error) {
+	summary := CurrentSummary{Temp: response.Data.Temperature, Condition: "Unknown"}
+	if current := response.Data.Conditions; current != nil {
+		summary.FeelsLike = &current.FeelsLike
+		summary.Condition = current.Condition
+		summary.Precip = current.Precipitation
+		summary.Wind = &current.WindSpeed
+	}
+	return buildResponse(summary)
+}
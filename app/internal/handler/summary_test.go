@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/weather"
+)
+
+func TestConditions(t *testing.T) {
+	tests := []struct {
+		name   string
+		values weather.WeatherDataValues
+		want   db.Conditions
+	}{
+		{"clear and dry", weather.WeatherDataValues{TemperatureApparent: 18.5, WeatherCode: 1000, WindSpeed: 3.2},
+			db.Conditions{FeelsLike: 18.5, Condition: "Clear", WindSpeed: 3.2}},
+		{"rain", weather.WeatherDataValues{WeatherCode: 4001, RainIntensity: 2}, db.Conditions{Condition: "Rain", Precipitation: true}},
+		{"snow", weather.WeatherDataValues{SnowIntensity: 1}, db.Conditions{Condition: "Unknown", Precipitation: true}},
+		{"sleet", weather.WeatherDataValues{SleetIntensity: 1}, db.Conditions{Condition: "Unknown", Precipitation: true}},
+		{"freezing rain", weather.WeatherDataValues{FreezingRainIntensity: 1}, db.Conditions{Condition: "Unknown", Precipitation: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := conditions(tt.values); *got != tt.want {
+				t.Errorf("conditions = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildSummaryResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		data db.WeatherData
+		want string
+	}{
+		{"with conditions", db.WeatherData{Temperature: 21.5, Conditions: &db.Conditions{FeelsLike: 20, Condition: "Rain", Precipitation: true, WindSpeed: 4.5}},
+			`{"temp":21.5,"feelsLike":20,"condition":"Rain","precip":true,"wind":4.5}`},
+		{"without conditions", db.WeatherData{Temperature: 12},
+			`{"temp":12,"feelsLike":null,"condition":"Unknown","precip":false,"wind":null}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := buildSummaryResponse(&Response{Data: tt.data})
+			if err != nil {
+				t.Fatalf("buildSummaryResponse: %v", err)
+			}
+			if response.Body != tt.want {
+				t.Errorf("body = %s, want %s", response.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummaryRequest(t *testing.T) {
+	setupHandler(t)
+	stubUpstream(t, func(*http.Request) (*http.Response, error) {
+		return jsonResponse(200, fmt.Sprintf(`{"data":{"time":%q,"values":{"temperature":20,"humidity":50,`+
+			`"temperatureApparent":18,"weatherCode":4001,"rainIntensity":2,"windSpeed":10}},"location":{"lat":1,"lon":2,"name":"Test"}}`,
+			time.Now().UTC().Format(time.RFC3339))), nil
+	})
+
+	tests := []struct {
+		units string
+		want  string
+	}{
+		{"metric", `{"temp":20,"feelsLike":18,"condition":"Rain","precip":true,"wind":10}`},
+		{"imperial", `{"temp":68,"feelsLike":64.4,"condition":"Rain","precip":true,"wind":22.4}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.units, func(t *testing.T) {
+			params := map[string]string{"city": uniqueCity(t), "summary": "true", "units": tt.units, "precision": "1"}
+			response, _ := HandleRequest(context.Background(), weatherRequest(params, nil))
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d; body %s", response.StatusCode, response.Body)
+			}
+			if response.Body != tt.want {
+				t.Errorf("body = %s, want %s", response.Body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"weather-lambda/internal/db"
+)
+
+// Response is the weather payload returned to clients. Transformers shape it
+// in place before it is marshalled.
+type Response struct {
+	Data   db.WeatherData
+	Fields []string
+
+	// Nulls lists requested fields kept in the body as null when absent
+	Nulls []string
+}
+
+func (r Response) MarshalJSON() ([]byte, error) {
+	body, err := json.Marshal(r.Data)
+	if err != nil || (len(r.Fields) == 0 && len(r.Nulls) == 0) {
+		return body, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(body, &all); err != nil {
+		return nil, err
+	}
+	for _, field := range r.Nulls {
+		if _, ok := all[field]; !ok {
+			all[field] = json.RawMessage("null")
+		}
+	}
+	if len(r.Fields) == 0 {
+		return json.Marshal(all)
+	}
+
+	projected := make(map[string]json.RawMessage, len(r.Fields))
+	for _, field := range r.Fields {
+		if value, ok := all[field]; ok {
+			projected[field] = value
+		}
+	}
+	return json.Marshal(projected)
+}
+
+type RequestOptions struct {
+	Units     string
+	Precision int
+	Fields    []string
+
+	// Region is the client's Accept-Language region, used to infer units
+	Region string
+
+	IncludeAirQuality bool
+	IncludeMoonPhase  bool
+	IncludeTrend      bool
+	IncludeDelta      bool
+	IncludeDailyRange bool
+
+	// Summary returns the condensed CurrentSummary instead of the full reading
+	Summary bool
+
+	// Format is the response encoding: json, geojson or msgpack
+	Format string
+
+	// UnitOverrides maps a field to units that take precedence over Units
+	UnitOverrides map[string]string
+}
+
+type ResponseTransformer func(*Response, RequestOptions) error
+
+// Transformers run in order, so conversions happen before rounding and
+// projection is applied last.
+var transformers = []ResponseTransformer{
+	filterAirQuality,
+	filterMoonPhase,
+	filterTrend,
+	filterConditions,
+	convertUnits,
+	roundValues,
+	projectFields,
+}
+
+var responseFields = []string{"City", "Temperature", "Humidity", "Time", "Provider", "DerivedFrom", "Degraded", "Location", "AirQuality", "MoonPhase", "Trend", "Delta", "DailyRange", "Severe", "SeverityReasons"}
+
+func parseRequestOptions(params map[string]string, defaultUnits string) (RequestOptions, error) {
+	// Units are left empty so they can be inferred from the location
+	opts := RequestOptions{Precision: -1}
+	if validUnits(defaultUnits) {
+		opts.Units = defaultUnits
+	}
+
+	if units := strings.ToLower(params["units"]); units != "" {
+		if !validUnits(units) {
+			return RequestOptions{}, fmt.Errorf("unsupported units: %q", units)
+		}
+		opts.Units = units
+	}
+
+	if precision := params["precision"]; precision != "" {
+		digits, err := strconv.Atoi(precision)
+		if err != nil || digits < 0 || digits > 6 {
+			return RequestOptions{}, fmt.Errorf("precision must be between 0 and 6")
+		}
+		opts.Precision = digits
+	}
+
+	opts.IncludeAirQuality = params["includeAirQuality"] == "true"
+	opts.IncludeMoonPhase = params["includeMoonPhase"] == "true"
+	opts.IncludeTrend = params["includeTrend"] == "true"
+	opts.IncludeDelta = params["includeDelta"] == "true"
+	opts.IncludeDailyRange = params["includeDailyRange"] == "true"
+	opts.Summary = params["summary"] == "true"
+
+	if value := params["unitOverrides"]; value != "" {
+		overrides, err := parseUnitOverrides(value)
+		if err != nil {
+			return RequestOptions{}, err
+		}
+		opts.UnitOverrides = overrides
+	}
+
+	switch format := strings.ToLower(params["format"]); format {
+	case "", "json", "msgpack", "geojson":
+		opts.Format = format
+	default:
+		return RequestOptions{}, fmt.Errorf("unsupported format: %q", format)
+	}
+
+	if fields := params["fields"]; fields != "" {
+		for _, name := range strings.Split(fields, ",") {
+			field, ok := canonicalField(strings.TrimSpace(name))
+			if !ok {
+				return RequestOptions{}, fmt.Errorf("unknown field: %q", name)
+			}
+			opts.Fields = append(opts.Fields, field)
+		}
+	}
+
+	return opts, nil
+}
+
+func validUnits(units string) bool {
+	return units == "metric" || units == "imperial"
+}
+
+func canonicalField(name string) (string, bool) {
+	for _, field := range responseFields {
+		if strings.EqualFold(field, name) {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+func applyTransformers(response *Response, opts RequestOptions) error {
+	for _, transform := range transformers {
+		if err := transform(response, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func filterAirQuality(response *Response, opts RequestOptions) error {
+	if !opts.IncludeAirQuality {
+		response.Data.AirQuality = nil
+	}
+	return nil
+}
+
+func filterMoonPhase(response *Response, opts RequestOptions) error {
+	if !opts.IncludeMoonPhase {
+		response.Data.MoonPhase = nil
+	}
+	return nil
+}
+
+func filterTrend(response *Response, opts RequestOptions) error {
+	if !opts.IncludeTrend {
+		response.Data.Trend = nil
+	}
+	if opts.IncludeDelta {
+		response.Nulls = append(response.Nulls, "Delta")
+	} else {
+		response.Data.Delta = nil
+	}
+	if opts.IncludeDailyRange {
+		response.Nulls = append(response.Nulls, "DailyRange")
+	} else {
+		response.Data.DailyRange = nil
+	}
+	return nil
+}
+
+func convertUnits(response *Response, opts RequestOptions) error {
+	if temperatureUnits(response.Data, opts) == "imperial" {
+		response.Data.Temperature = response.Data.Temperature*9/5 + 32
+		updateTemperatureChanges(response, func(delta float64) float64 { return delta * 9 / 5 })
+		updateDailyRange(response, func(temperature float64) float64 { return temperature*9/5 + 32 })
+		updateConditions(response, func(temperature float64) float64 { return temperature*9/5 + 32 }, nil)
+	}
+	if windUnits(response.Data, opts) == "imperial" {
+		updateConditions(response, nil, func(speed float64) float64 { return speed * metersPerSecondToMph })
+	}
+	return nil
+}
+
+func roundValues(response *Response, opts RequestOptions) error {
+	if opts.Precision >= 0 {
+		scale := math.Pow(10, float64(opts.Precision))
+		response.Data.Temperature = math.Round(response.Data.Temperature*scale) / scale
+		updateTemperatureChanges(response, func(delta float64) float64 { return math.Round(delta*scale) / scale })
+		updateDailyRange(response, func(temperature float64) float64 { return math.Round(temperature*scale) / scale })
+		round := func(value float64) float64 { return math.Round(value*scale) / scale }
+		updateConditions(response, round, round)
+	}
+	return nil
+}
+
+// updateTemperatureChanges rewrites the trend and delta temperatures on
+// copies, since both are shared with the cached reading.
+func updateTemperatureChanges(response *Response, update func(float64) float64) {
+	if response.Data.Trend != nil {
+		trend := *response.Data.Trend
+		trend.Delta = update(trend.Delta)
+		response.Data.Trend = &trend
+	}
+	if response.Data.Delta != nil {
+		delta := *response.Data.Delta
+		delta.Temperature = update(delta.Temperature)
+		response.Data.Delta = &delta
+	}
+}
+
+// updateDailyRange rewrites the daily range temperatures on a copy, since
+// the range is shared with the cached reading.
+func updateDailyRange(response *Response, update func(float64) float64) {
+	if response.Data.DailyRange == nil {
+		return
+	}
+	daily := *response.Data.DailyRange
+	daily.MinTemperature = update(daily.MinTemperature)
+	daily.MaxTemperature = update(daily.MaxTemperature)
+	response.Data.DailyRange = &daily
+}
+
+func filterConditions(response *Response, opts RequestOptions) error {
+	if !opts.Summary {
+		response.Data.Conditions = nil
+	}
+	return nil
+}
+
+// updateConditions rewrites the feels-like temperature and wind speed on a
+// copy, since the conditions are shared with the cached reading. A nil
+// update leaves that value as it is.
+func updateConditions(response *Response, temperature func(float64) float64, speed func(float64) float64) {
+	if response.Data.Conditions == nil {
+		return
+	}
+	current := *response.Data.Conditions
+	if temperature != nil {
+		current.FeelsLike = temperature(current.FeelsLike)
+	}
+	if speed != nil {
+		current.WindSpeed = speed(current.WindSpeed)
+	}
+	response.Data.Conditions = &current
+}
+
+func projectFields(response *Response, opts RequestOptions) error {
+	response.Fields = opts.Fields
+	return nil
+}
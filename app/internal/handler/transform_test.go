@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+
+	"weather-lambda/internal/db"
+)
+
+func TestConvertUnits(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          RequestOptions
+		wantTemp      float64
+		wantFeelsLike float64
+		wantWind      float64
+		wantRangeMax  float64
+		wantTrend     float64
+	}{
+		{"metric leaves values", RequestOptions{Units: "metric"}, 20, 18, 10, 25, 2},
+		{"imperial converts everything", RequestOptions{Units: "imperial"}, 68, 64.4, 10 * metersPerSecondToMph, 77, 3.6},
+		{"temp override alone", RequestOptions{Units: "metric", UnitOverrides: map[string]string{"temp": "imperial"}}, 68, 64.4, 10, 77, 3.6},
+		{"wind override alone", RequestOptions{Units: "metric", UnitOverrides: map[string]string{"wind": "imperial"}}, 20, 18, 10 * metersPerSecondToMph, 25, 2},
+		{"wind override keeps metres per second", RequestOptions{Units: "imperial", UnitOverrides: map[string]string{"wind": "metric"}}, 68, 64.4, 10, 77, 3.6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &Response{Data: db.WeatherData{
+				Temperature: 20,
+				Conditions:  &db.Conditions{FeelsLike: 18, WindSpeed: 10},
+				DailyRange:  &db.DailyRange{MinTemperature: 15, MaxTemperature: 25},
+				Trend:       &db.Trend{Delta: 2},
+			}}
+			if err := convertUnits(response, tt.opts); err != nil {
+				t.Fatalf("convertUnits: %v", err)
+			}
+			got := response.Data
+			if !closeTo(got.Temperature, tt.wantTemp) {
+				t.Errorf("Temperature = %v, want %v", got.Temperature, tt.wantTemp)
+			}
+			if !closeTo(got.Conditions.FeelsLike, tt.wantFeelsLike) {
+				t.Errorf("FeelsLike = %v, want %v", got.Conditions.FeelsLike, tt.wantFeelsLike)
+			}
+			if !closeTo(got.Conditions.WindSpeed, tt.wantWind) {
+				t.Errorf("WindSpeed = %v, want %v", got.Conditions.WindSpeed, tt.wantWind)
+			}
+			if !closeTo(got.DailyRange.MaxTemperature, tt.wantRangeMax) {
+				t.Errorf("DailyRange.MaxTemperature = %v, want %v", got.DailyRange.MaxTemperature, tt.wantRangeMax)
+			}
+			if !closeTo(got.Trend.Delta, tt.wantTrend) {
+				t.Errorf("Trend.Delta = %v, want %v", got.Trend.Delta, tt.wantTrend)
+			}
+		})
+	}
+}
+
+func TestConvertUnitsCopiesSharedValues(t *testing.T) {
+	conditions := &db.Conditions{FeelsLike: 18, WindSpeed: 10}
+	response := &Response{Data: db.WeatherData{Temperature: 20, Conditions: conditions}}
+	if err := convertUnits(response, RequestOptions{Units: "imperial"}); err != nil {
+		t.Fatalf("convertUnits: %v", err)
+	}
+	if conditions.FeelsLike != 18 || conditions.WindSpeed != 10 {
+		t.Errorf("cached conditions were modified: %+v", *conditions)
+	}
+}
+
+func TestRoundValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		precision int
+		wantTemp  float64
+		wantWind  float64
+		wantDelta float64
+	}{
+		{"unset precision leaves values", -1, 21.456, 3.149, 1.25},
+		{"zero digits", 0, 21, 3, 1},
+		{"one digit", 1, 21.5, 3.1, 1.3},
+		{"two digits", 2, 21.46, 3.15, 1.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &Response{Data: db.WeatherData{
+				Temperature: 21.456,
+				Conditions:  &db.Conditions{WindSpeed: 3.149},
+				Delta:       &db.Delta{Temperature: 1.25},
+			}}
+			if err := roundValues(response, RequestOptions{Precision: tt.precision}); err != nil {
+				t.Fatalf("roundValues: %v", err)
+			}
+			got := response.Data
+			if !closeTo(got.Temperature, tt.wantTemp) {
+				t.Errorf("Temperature = %v, want %v", got.Temperature, tt.wantTemp)
+			}
+			if !closeTo(got.Conditions.WindSpeed, tt.wantWind) {
+				t.Errorf("WindSpeed = %v, want %v", got.Conditions.WindSpeed, tt.wantWind)
+			}
+			if !closeTo(got.Delta.Temperature, tt.wantDelta) {
+				t.Errorf("Delta.Temperature = %v, want %v", got.Delta.Temperature, tt.wantDelta)
+			}
+		})
+	}
+}
+
+func TestProjectFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []string
+		want   []string
+	}{
+		{"no fields keeps everything", nil, []string{"City", "Humidity", "Severe", "Temperature", "Time"}},
+		{"single field", []string{"Temperature"}, []string{"Temperature"}},
+		{"several fields", []string{"City", "Humidity"}, []string{"City", "Humidity"}},
+		{"absent field is dropped", []string{"City", "AirQuality"}, []string{"City"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &Response{Data: db.WeatherData{City: "Toronto", Temperature: 20, Humidity: 50, Time: "2024-03-01 12:00"}}
+			if err := projectFields(response, RequestOptions{Fields: tt.fields}); err != nil {
+				t.Fatalf("projectFields: %v", err)
+			}
+			if got := marshalledKeys(t, response); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keys = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformersConvertBeforeRounding(t *testing.T) {
+	// 21.25°C is 70.25°F; rounding first would give 21°C, i.e. 69.8°F
+	response := &Response{Data: db.WeatherData{Temperature: 21.25}}
+	opts := RequestOptions{Units: "imperial", Precision: 0}
+	if err := applyTransformers(response, opts); err != nil {
+		t.Fatalf("applyTransformers: %v", err)
+	}
+	if response.Data.Temperature != 70 {
+		t.Errorf("Temperature = %v, want 70", response.Data.Temperature)
+	}
+}
+
+func TestTransformersProjectLast(t *testing.T) {
+	last := transformers[len(transformers)-1]
+	if reflect.ValueOf(last).Pointer() != reflect.ValueOf(projectFields).Pointer() {
+		t.Fatal("projectFields is not the last transformer")
+	}
+
+	// A projected field still carries the converted, rounded value
+	response := &Response{Data: db.WeatherData{City: "Austin", Temperature: 21.25}}
+	opts := RequestOptions{Units: "imperial", Precision: 0, Fields: []string{"Temperature"}}
+	if err := applyTransformers(response, opts); err != nil {
+		t.Fatalf("applyTransformers: %v", err)
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(body) != `{"Temperature":70}` {
+		t.Errorf("body = %s, want {\"Temperature\":70}", body)
+	}
+}
+
+func marshalledKeys(t *testing.T, response *Response) []string {
+	t.Helper()
+	body, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func closeTo(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/log"
+)
+
+// steadyDelta is the smallest temperature change, in °C, reported as a trend.
+const steadyDelta = 0.5
+
+// previousReading returns the reading stored for the city before current,
+// which trends and deltas are measured against.
+func previousReading(ctx context.Context, current db.WeatherData) (db.WeatherData, bool) {
+	previous, found, err := store.Get(ctx, current.City)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error reading previous data: %v", err))
+		return db.WeatherData{}, false
+	}
+	if !found || previous.Time == current.Time {
+		return db.WeatherData{}, false
+	}
+	return previous, true
+}
+
+// compareWithPrevious fills in the trend, delta and daily range the request
+// asked for. They stay nil when there is no earlier reading.
+func compareWithPrevious(ctx context.Context, current *db.WeatherData, opts RequestOptions) {
+	if !opts.IncludeTrend && !opts.IncludeDelta && !opts.IncludeDailyRange {
+		return
+	}
+
+	previous, found := previousReading(ctx, *current)
+	if !found {
+		return
+	}
+	if opts.IncludeTrend {
+		current.Trend = temperatureTrend(*current, previous)
+	}
+	if opts.IncludeDelta {
+		current.Delta = &db.Delta{
+			Temperature: current.Temperature - previous.Temperature,
+			Humidity:    current.Humidity - previous.Humidity,
+			Since:       previous.Time,
+		}
+	}
+	if opts.IncludeDailyRange {
+		current.DailyRange = dailyRange(*current, previous)
+	}
+}
+
+// temperatureTrend classifies the temperature change since previous.
+func temperatureTrend(current db.WeatherData, previous db.WeatherData) *db.Trend {
+	delta := current.Temperature - previous.Temperature
+	trend := &db.Trend{Direction: "steady", Delta: delta, Since: previous.Time}
+	switch {
+	case math.Abs(delta) < steadyDelta:
+	case delta > 0:
+		trend.Direction = "rising"
+	default:
+		trend.Direction = "falling"
+	}
+	return trend
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"weather-lambda/internal/db"
+)
+
+func TestTemperatureTrend(t *testing.T) {
+	earlier := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name          string
+		previous      *float64
+		wantDirection string
+	}{
+		{"rising", ptr(18.0), "rising"},
+		{"falling", ptr(23.0), "falling"},
+		{"steady", ptr(20.2), "steady"},
+		{"no previous reading", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := setupHandler(t)
+			city := uniqueCity(t)
+			if tt.previous != nil {
+				memory.Save(context.Background(), db.WeatherData{City: city, Temperature: *tt.previous, Time: earlier})
+			}
+			stubUpstream(t, func(*http.Request) (*http.Response, error) {
+				return jsonResponse(200, realtimeBody(20.5, 50)), nil
+			})
+
+			response, _ := HandleRequest(context.Background(), weatherRequest(map[string]string{"city": city, "includeTrend": "true"}, nil))
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want 200", response.StatusCode)
+			}
+			trend := decodeReading(t, response).Trend
+			if tt.wantDirection == "" {
+				if trend != nil {
+					t.Errorf("Trend = %+v, want none without a previous reading", trend)
+				}
+				return
+			}
+			if trend == nil {
+				t.Fatalf("Trend missing")
+			}
+			if trend.Direction != tt.wantDirection || trend.Since != earlier {
+				t.Errorf("Trend = %+v, want %s since %s", trend, tt.wantDirection, earlier)
+			}
+			if want := 20.5 - *tt.previous; trend.Delta != want {
+				t.Errorf("Delta = %v, want %v", trend.Delta, want)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
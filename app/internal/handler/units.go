@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"strings"
+
+	"weather-lambda/internal/db"
+)
+
+// Countries that use imperial units for weather, keyed by ISO 3166 code.
+var imperialCountries = map[string]bool{
+	"US": true,
+	"LR": true,
+	"MM": true,
+}
+
+// Country names as they appear at the end of tomorrow.io location names.
+var countryCodes = map[string]string{
+	"united states":            "US",
+	"united states of america": "US",
+	"liberia":                  "LR",
+	"myanmar":                  "MM",
+}
+
+// resolveUnits picks explicit units first, then infers them from the
+// location's country, then from the client's Accept-Language region.
+func resolveUnits(data db.WeatherData, opts RequestOptions) string {
+	if opts.Units != "" {
+		return opts.Units
+	}
+
+	country := opts.Region
+	if data.Location != nil {
+		if code := locationCountry(data.Location.Name); code != "" {
+			country = code
+		}
+	}
+	return unitsForCountry(country)
+}
+
+func unitsForCountry(country string) string {
+	if imperialCountries[strings.ToUpper(country)] {
+		return "imperial"
+	}
+	return "metric"
+}
+
+func locationCountry(name string) string {
+	parts := strings.Split(name, ",")
+	last := strings.ToLower(strings.TrimSpace(parts[len(parts)-1]))
+	return countryCodes[last]
+}
+
+// acceptLanguageRegion returns the region of the client's preferred language,
+// e.g. "US" for "en-US,en;q=0.9".
+func acceptLanguageRegion(headers map[string]string) string {
+	preferred := strings.Split(headerValue(headers, "Accept-Language"), ",")[0]
+	tag := strings.TrimSpace(strings.Split(preferred, ";")[0])
+	subtags := strings.FieldsFunc(tag, func(r rune) bool { return r == '-' || r == '_' })
+	for _, subtag := range subtags[min(1, len(subtags)):] {
+		if len(subtag) == 2 {
+			return strings.ToUpper(subtag)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"testing"
+
+	"weather-lambda/internal/db"
+)
+
+func TestResolveUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		opts     RequestOptions
+		want     string
+	}{
+		{"US location", "Austin, Texas, United States", RequestOptions{}, "imperial"},
+		{"GB location", "London, England, United Kingdom", RequestOptions{}, "metric"},
+		{"explicit units win", "Austin, Texas, United States", RequestOptions{Units: "metric"}, "metric"},
+		{"region when location is unknown", "Somewhere", RequestOptions{Region: "US"}, "imperial"},
+		{"location beats region", "Austin, Texas, United States", RequestOptions{Region: "GB"}, "imperial"},
+		{"nothing known", "", RequestOptions{}, "metric"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := db.WeatherData{Location: &db.Location{Name: tt.location}}
+			if got := resolveUnits(data, tt.opts); got != tt.want {
+				t.Errorf("resolveUnits = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAcceptLanguageRegion(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"en-US,en;q=0.9", "US"},
+		{"en-GB", "GB"},
+		{"zh-Hant-TW", "TW"},
+		{"fr", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := acceptLanguageRegion(map[string]string{"Accept-Language": tt.header}); got != tt.want {
+			t.Errorf("acceptLanguageRegion(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// viewerLocation returns "lat,lon" from CloudFront's viewer geolocation
+// headers, for requests that name no location. It is enabled by the
+// viewer-geo feature and ignores missing, NaN or out-of-range values.
+func viewerLocation(headers map[string]string) (string, bool) {
+	if !features.Enabled("viewer-geo") {
+		return "", false
+	}
+
+	lat, err := strconv.ParseFloat(headerValue(headers, "CloudFront-Viewer-Latitude"), 64)
+	if err != nil || math.IsNaN(lat) || lat < -90 || lat > 90 {
+		return "", false
+	}
+	lon, err := strconv.ParseFloat(headerValue(headers, "CloudFront-Viewer-Longitude"), 64)
+	if err != nil || math.IsNaN(lon) || lon < -180 || lon > 180 {
+		return "", false
+	}
+	return fmt.Sprintf("%g,%g", lat, lon), true
+}
@@ -0,0 +1,48 @@
+package handler
+
+import "testing"
+
+func TestViewerLocation(t *testing.T) {
+	setupHandler(t, "viewer-geo")
+
+	tests := []struct {
+		name     string
+		lat, lon string
+		want     string
+		wantOK   bool
+	}{
+		{"valid", "51.5", "-0.12", "51.5,-0.12", true},
+		{"edge of range", "-90", "180", "-90,180", true},
+		{"missing latitude", "", "-0.12", "", false},
+		{"not a number", "north", "-0.12", "", false},
+		{"latitude out of range", "91", "0", "", false},
+		{"longitude out of range", "0", "-181", "", false},
+		{"NaN latitude", "NaN", "0", "", false},
+		{"NaN longitude", "0", "nan", "", false},
+		{"infinite latitude", "+Inf", "0", "", false},
+		{"infinite longitude", "0", "-Inf", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{
+				"CloudFront-Viewer-Latitude":  tt.lat,
+				"CloudFront-Viewer-Longitude": tt.lon,
+			}
+			got, ok := viewerLocation(headers)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("viewerLocation = %q, %v; want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestViewerLocationNeedsFeature(t *testing.T) {
+	setupHandler(t)
+	headers := map[string]string{
+		"CloudFront-Viewer-Latitude":  "51.5",
+		"CloudFront-Viewer-Longitude": "-0.12",
+	}
+	if got, ok := viewerLocation(headers); ok {
+		t.Errorf("viewerLocation = %q with viewer-geo disabled", got)
+	}
+}
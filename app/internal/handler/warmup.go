@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const warmupHeader = "X-Warmup"
+
+// Event is the Lambda payload: an API Gateway request, or a scheduled
+// keep-warm ping of the form {"warmup": true}.
+type Event struct {
+	events.APIGatewayProxyRequest
+	Warmup bool `json:"warmup"`
+}
+
+func isWarmup(event Event) bool {
+	return event.Warmup || headerValue(event.Headers, warmupHeader) == "true"
+}
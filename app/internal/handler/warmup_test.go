@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"weather-lambda/internal/db"
+)
+
+// recordingStore counts every call that reaches the store, and the writes
+// among them.
+type recordingStore struct {
+	calls int
+	saves int
+}
+
+func (s *recordingStore) Save(context.Context, db.WeatherData) error {
+	s.calls++
+	s.saves++
+	return nil
+}
+
+func (s *recordingStore) Get(context.Context, string) (db.WeatherData, bool, error) {
+	s.calls++
+	return db.WeatherData{}, false, nil
+}
+
+func TestWarmupShortCircuits(t *testing.T) {
+	var scheduled Event
+	if err := json.Unmarshal([]byte(`{"warmup": true}`), &scheduled); err != nil {
+		t.Fatalf("decode warm-up event: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		event Event
+	}{
+		{"scheduled event", scheduled},
+		{"header", weatherRequest(map[string]string{"city": uniqueCity(t)}, map[string]string{"X-Warmup": "true"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupHandler(t)
+			recording := &recordingStore{}
+			store = recording
+			upstreamCalls := 0
+			stubUpstream(t, func(*http.Request) (*http.Response, error) {
+				upstreamCalls++
+				return jsonResponse(200, realtimeBody(20, 50)), nil
+			})
+
+			response, err := HandleRequest(context.Background(), tt.event)
+			if err != nil {
+				t.Fatalf("HandleRequest: %v", err)
+			}
+			if response.StatusCode != 200 {
+				t.Errorf("status = %d, want 200", response.StatusCode)
+			}
+			if upstreamCalls != 0 || recording.calls != 0 {
+				t.Errorf("warm-up reached upstream %d times and the store %d times", upstreamCalls, recording.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"os"
+	"sync"
+)
+
+// writeLocks serializes the store write and cache set for a cache key within
+// this container, so two requests that both refetched an expired city cannot
+// interleave and leave the table and cache holding different readings.
+var writeLocks = keyedLocks{entries: map[string]*keyedLock{}}
+
+type keyedLock struct {
+	mu      sync.Mutex
+	waiters int
+
+	// written is the Time of the newest reading persisted under the lock
+	written string
+}
+
+type keyedLocks struct {
+	mu      sync.Mutex
+	entries map[string]*keyedLock
+}
+
+// lock blocks until the caller holds the key. Entries are dropped once no
+// caller holds or waits on them, so the map only grows with in-flight keys.
+func (l *keyedLocks) lock(key string) (*keyedLock, func()) {
+	l.mu.Lock()
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &keyedLock{}
+		l.entries[key] = entry
+	}
+	entry.waiters++
+	l.mu.Unlock()
+
+	entry.mu.Lock()
+	return entry, func() {
+		entry.mu.Unlock()
+		l.mu.Lock()
+		entry.waiters--
+		if entry.waiters == 0 {
+			delete(l.entries, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// writeCoordination reads WRITE_COORDINATION. per-key, the default, holds a
+// lock per cache key around the write; none lets concurrent writes race.
+func writeCoordination() string {
+	if os.Getenv("WRITE_COORDINATION") == "none" {
+		return "none"
+	}
+	return "per-key"
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/db"
+)
+
+// slowStore holds each write long enough for concurrent writers to queue.
+type slowStore struct {
+	*db.MemoryStore
+}
+
+func (s slowStore) Save(ctx context.Context, data db.WeatherData) error {
+	time.Sleep(10 * time.Millisecond)
+	return s.MemoryStore.Save(ctx, data)
+}
+
+func TestConcurrentWritesKeepTheNewestReading(t *testing.T) {
+	memory := setupHandler(t)
+	store = slowStore{memory}
+
+	city := uniqueCity(t)
+	cacheKey := weatherCacheKey(city, RequestOptions{})
+	base := time.Now().UTC().Truncate(time.Second)
+	const writers = 8
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < writers; i++ {
+		reading := db.WeatherData{City: city, Temperature: float64(i), Time: base.Add(time.Duration(i) * time.Minute).Format(time.RFC3339)}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if err := persist(context.Background(), cacheKey, reading); err != nil {
+				t.Errorf("persist: %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	stored, found, _ := memory.Get(context.Background(), city)
+	if !found || stored.Temperature != writers-1 {
+		t.Errorf("stored temperature = %v (found %v), want the newest, %d", stored.Temperature, found, writers-1)
+	}
+	cached, found := cache.GetCache(cacheKey)
+	if reading, _ := cached.(db.WeatherData); !found || reading.Temperature != writers-1 {
+		t.Errorf("cached = %+v (found %v), want the newest reading", cached, found)
+	}
+}
+
+func TestKeyedLocksDropIdleEntries(t *testing.T) {
+	locks := keyedLocks{entries: map[string]*keyedLock{}}
+
+	_, unlockFirst := locks.lock("city")
+	acquired := make(chan struct{})
+	go func() {
+		_, unlock := locks.lock("city")
+		close(acquired)
+		unlock()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second caller acquired a held key")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlockFirst()
+	<-acquired
+
+	// The second caller may still be releasing; wait for it
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		locks.mu.Lock()
+		remaining := len(locks.entries)
+		locks.mu.Unlock()
+		if remaining == 0 {
+			return
+		}
+	}
+	t.Errorf("entries were not dropped once idle")
+}
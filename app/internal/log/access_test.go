@@ -0,0 +1,49 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCommonLogFormat(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+	tests := []struct {
+		name   string
+		redact string
+		entry  AccessEntry
+		want   string
+	}{
+		{
+			"full entry",
+			"",
+			AccessEntry{Host: "203.0.113.7", Method: "GET", Path: "/weather", Query: map[string]string{"units": "metric", "city": "Paris"}, Status: 200, Bytes: 512, Time: at},
+			`203.0.113.7 - - [01/Mar/2024:12:30:45 +0000] "GET /weather?city=Paris&units=metric HTTP/1.1" 200 512`,
+		},
+		{
+			"spaces and separators are encoded",
+			"",
+			AccessEntry{Method: "GET", Path: "/weather", Query: map[string]string{"city": "New York", "note": "a&b=c"}, Status: 200, Bytes: 10, Time: at},
+			`- - - [01/Mar/2024:12:30:45 +0000] "GET /weather?city=New+York&note=a%26b%3Dc HTTP/1.1" 200 10`,
+		},
+		{
+			"redacted values are encoded",
+			"lat",
+			AccessEntry{Method: "GET", Path: "/weather", Query: map[string]string{"lat": "48.85 N"}, Status: 404, Time: at},
+			`- - - [01/Mar/2024:12:30:45 +0000] "GET /weather?lat=%2A%2A%2A HTTP/1.1" 404 -`,
+		},
+		{
+			"no query",
+			"",
+			AccessEntry{Method: "GET", Path: "/health", Status: 200, Bytes: 2, Time: at},
+			`- - - [01/Mar/2024:12:30:45 +0000] "GET /health HTTP/1.1" 200 2`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LOG_REDACT_PARAMS", tt.redact)
+			if got := CommonLogFormat(tt.entry); got != tt.want {
+				t.Errorf("CommonLogFormat =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,14 @@
 package log
 
 import (
+	"context"
 	"log"
 	"os"
 )
 
 var (
 	infoLogger  = log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile)
+	warnLogger  = log.New(os.Stdout, "WARN: ", log.Ldate|log.Ltime|log.Lshortfile)
 	errorLogger = log.New(os.Stderr, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile)
 )
 
@@ -17,3 +19,39 @@ func Info(msg string) {
 func Error(msg string) {
 	errorLogger.Println(msg)
 }
+
+func Warn(msg string) {
+	warnLogger.Println(msg)
+}
+
+// InfoContext logs msg prefixed with the request ID carried by ctx, so lines
+// from background work stay tagged with the request that started it.
+func InfoContext(ctx context.Context, msg string) {
+	infoLogger.Println(withRequestID(ctx, msg))
+}
+
+func ErrorContext(ctx context.Context, msg string) {
+	errorLogger.Println(withRequestID(ctx, msg))
+}
+
+func WarnContext(ctx context.Context, msg string) {
+	warnLogger.Println(withRequestID(ctx, msg))
+}
+
+func withRequestID(ctx context.Context, msg string) string {
+	if id := RequestID(ctx); id != "" {
+		return "[" + id + "] " + msg
+	}
+	return msg
+}
+
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,57 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRequestIDPrefix(t *testing.T) {
+	var buf bytes.Buffer
+	infoLogger.SetOutput(&buf)
+	t.Cleanup(func() { infoLogger.SetOutput(os.Stdout) })
+
+	InfoContext(WithRequestID(context.Background(), "abc123"), "fetching")
+	InfoContext(context.Background(), "idle")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasSuffix(lines[0], "[abc123] fetching") {
+		t.Errorf("line with a request ID = %q, want the request ID prefix", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], ": idle") {
+		t.Errorf("line without a request ID = %q, want no prefix", lines[1])
+	}
+}
+
+func TestRequestIDPrefixConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	infoLogger.SetOutput(&buf)
+	t.Cleanup(func() { infoLogger.SetOutput(os.Stdout) })
+
+	// Each goroutine's lines carry its own ID even while others interleave
+	var wg sync.WaitGroup
+	for _, id := range []string{"a", "b", "c"} {
+		wg.Add(1)
+		go func(ctx context.Context, id string) {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				InfoContext(ctx, "from "+id)
+			}
+		}(WithRequestID(context.Background(), id), id)
+	}
+	wg.Wait()
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		_, msg, _ := strings.Cut(line, "[")
+		id, rest, _ := strings.Cut(msg, "] ")
+		if rest != "from "+id {
+			t.Errorf("line %q is tagged with the wrong request ID", line)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package log
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+const redacted = "***"
+
+// QueryString renders query parameters for a log line in a stable order,
+// replacing the values of any named in LOG_REDACT_PARAMS with ***.
+func QueryString(params map[string]string) string {
+	params = redactParams(params)
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+"="+params[name])
+	}
+	return strings.Join(pairs, "&")
+}
+
+// redactParams returns a copy of params with the values of any named in
+// LOG_REDACT_PARAMS replaced.
+func redactParams(params map[string]string) map[string]string {
+	redact := map[string]bool{}
+	for _, name := range strings.Split(os.Getenv("LOG_REDACT_PARAMS"), ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			redact[name] = true
+		}
+	}
+
+	safe := make(map[string]string, len(params))
+	for name, value := range params {
+		if redact[strings.ToLower(name)] {
+			value = redacted
+		}
+		safe[name] = value
+	}
+	return safe
+}
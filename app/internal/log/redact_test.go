@@ -0,0 +1,45 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestQueryString(t *testing.T) {
+	params := map[string]string{"city": "Paris", "lat": "48.85", "Lon": "2.35"}
+	tests := []struct {
+		redact string
+		want   string
+	}{
+		{"", "Lon=2.35&city=Paris&lat=48.85"},
+		{"lat,lon", "Lon=***&city=Paris&lat=***"},
+		{" LAT , ", "Lon=2.35&city=Paris&lat=***"},
+	}
+	for _, tt := range tests {
+		t.Setenv("LOG_REDACT_PARAMS", tt.redact)
+		if got := QueryString(params); got != tt.want {
+			t.Errorf("QueryString with %q redacted = %q, want %q", tt.redact, got, tt.want)
+		}
+	}
+}
+
+func TestAccessLogRedacts(t *testing.T) {
+	t.Setenv("LOG_REDACT_PARAMS", "lat,lon")
+	var buf bytes.Buffer
+	infoLogger.SetOutput(&buf)
+	t.Cleanup(func() { infoLogger.SetOutput(os.Stdout) })
+
+	Access(AccessEntry{Method: "GET", Path: "/weather", Query: map[string]string{"lat": "48.85", "lon": "2.35", "units": "metric"}, Status: 200})
+
+	line := buf.String()
+	for _, secret := range []string{"48.85", "2.35"} {
+		if strings.Contains(line, secret) {
+			t.Errorf("redacted value %s appears in %q", secret, line)
+		}
+	}
+	if !strings.Contains(line, "lat=***") || !strings.Contains(line, "units=metric") {
+		t.Errorf("log line %q is missing the redacted or plain parameters", line)
+	}
+}
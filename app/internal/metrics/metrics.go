@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The registry is process-local, so values accumulate across warm invocations
+// of the same container and reset on cold start.
+var (
+	Requests          = newCounter("weather_requests_total", "Total number of requests handled.")
+	CacheHits         = newCounter("weather_cache_hits_total", "Total number of cache hits.")
+	CacheMisses       = newCounter("weather_cache_misses_total", "Total number of cache misses.")
+	UpstreamErrors    = newCounter("weather_upstream_errors_total", "Total number of failed upstream weather fetches.")
+	UpstreamAnomalies = newCounter("weather_upstream_anomalies_total", "Total number of upstream responses with missing or implausible values.")
+	DeadlineWarnings  = newCounter("weather_deadline_warnings_total", "Total number of requests that used most of their time budget.")
+	DegradedResponses = newCounter("weather_upstream_degraded_total", "Total number of upstream responses with too few populated values.")
+	ColdStarts        = newCounter("weather_cold_starts_total", "Total number of container cold starts.")
+	UpstreamQuota     = newGauge("weather_upstream_quota_remaining", "Upstream requests remaining in the current rate-limit window, or -1 if unknown.")
+	RequestLatency    = newHistogram("weather_request_duration_seconds", "Request latency in seconds.",
+		[]float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10})
+)
+
+var (
+	registryMu sync.Mutex
+	registry   []metric
+)
+
+type metric interface {
+	write(b *strings.Builder)
+}
+
+type Counter struct {
+	name  string
+	help  string
+	value atomic.Uint64
+}
+
+func newCounter(name, help string) *Counter {
+	c := &Counter{name: name, help: help}
+	register(c)
+	return c
+}
+
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+func (c *Counter) write(b *strings.Builder) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", c.name, c.help, c.name, c.name, c.value.Load())
+}
+
+type Gauge struct {
+	name  string
+	help  string
+	value atomic.Int64
+}
+
+func newGauge(name, help string) *Gauge {
+	g := &Gauge{name: name, help: help}
+	g.value.Store(-1)
+	register(g)
+	return g
+}
+
+func (g *Gauge) Set(value int64) {
+	g.value.Store(value)
+}
+
+func (g *Gauge) write(b *strings.Builder) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", g.name, g.help, g.name, g.name, g.value.Load())
+}
+
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64
+
+	mu     sync.Mutex
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(name, help string, buckets []float64) *Histogram {
+	h := &Histogram{name: name, help: help, buckets: buckets, counts: make([]uint64, len(buckets))}
+	register(h)
+	return h
+}
+
+func (h *Histogram) Observe(d time.Duration) {
+	seconds := d.Seconds()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, bound := range h.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (h *Histogram) write(b *strings.Builder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for i, bound := range h.buckets {
+		fmt.Fprintf(b, "%s_bucket{le=\"%s\"} %d\n", h.name, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+	}
+	fmt.Fprintf(b, "%s_bucket{le=\"+Inf\"} %d\n", h.name, h.count)
+	fmt.Fprintf(b, "%s_sum %s\n", h.name, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(b, "%s_count %d\n", h.name, h.count)
+}
+
+func register(m metric) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, m)
+}
+
+// Render returns every registered metric in the Prometheus text exposition format.
+func Render() string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	var b strings.Builder
+	for _, m := range registry {
+		m.write(&b)
+	}
+	return b.String()
+}
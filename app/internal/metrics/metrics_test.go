@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var sampleLine = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*(\{[a-zA-Z_][a-zA-Z0-9_]*="[^"]*"\})? -?[0-9.e+-]+$`)
+
+func TestRenderExpositionFormat(t *testing.T) {
+	Requests.Inc()
+	RequestLatency.Observe(300 * time.Millisecond)
+
+	samples := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(Render()))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "# HELP ") || strings.HasPrefix(line, "# TYPE ") {
+			continue
+		}
+		if !sampleLine.MatchString(line) {
+			t.Fatalf("line does not parse as a sample: %q", line)
+		}
+		name := line[:strings.IndexAny(line, "{ ")]
+		samples[name] = true
+	}
+
+	for _, name := range []string{
+		"weather_requests_total",
+		"weather_cache_hits_total",
+		"weather_cache_misses_total",
+		"weather_upstream_errors_total",
+		"weather_request_duration_seconds_bucket",
+		"weather_request_duration_seconds_sum",
+		"weather_request_duration_seconds_count",
+	} {
+		if !samples[name] {
+			t.Errorf("missing metric %s", name)
+		}
+	}
+}
+
+func TestHistogramBucketsAreCumulative(t *testing.T) {
+	h := &Histogram{name: "test_seconds", buckets: []float64{0.1, 1}, counts: make([]uint64, 2)}
+	h.Observe(50 * time.Millisecond)
+	h.Observe(500 * time.Millisecond)
+	h.Observe(5 * time.Second)
+
+	var b strings.Builder
+	h.write(&b)
+	for _, want := range []string{
+		`test_seconds_bucket{le="0.1"} 1`,
+		`test_seconds_bucket{le="1"} 2`,
+		`test_seconds_bucket{le="+Inf"} 3`,
+		`test_seconds_count 3`,
+	} {
+		if !strings.Contains(b.String(), want+"\n") {
+			t.Errorf("missing %q in:\n%s", want, b.String())
+		}
+	}
+}
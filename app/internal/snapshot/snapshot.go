@@ -0,0 +1,100 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"weather-lambda/internal/cache"
+	"weather-lambda/internal/db"
+	"weather-lambda/internal/log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+const keyPrefix = "snapshots/"
+
+// Enabled reports whether SNAPSHOT_BUCKET names a bucket to snapshot into.
+func Enabled() bool {
+	return bucket() != ""
+}
+
+func bucket() string {
+	return os.Getenv("SNAPSHOT_BUCKET")
+}
+
+func newClient() *s3.S3 {
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region: aws.String(os.Getenv("AWS_REGION")),
+	}))
+	return s3.New(sess)
+}
+
+// objectKey reuses the cache key so spellings of a city share one object.
+func objectKey(city string) string {
+	return keyPrefix + cache.Key(city) + ".json"
+}
+
+// Save overwrites the last good snapshot for data.City.
+func Save(ctx context.Context, data db.WeatherData) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error marshalling snapshot: %v", err))
+		return err
+	}
+
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(bucket()),
+		Key:         aws.String(objectKey(data.City)),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	}
+
+	if _, err := newClient().PutObjectWithContext(ctx, input); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error saving snapshot to S3: %v", err))
+		return err
+	}
+
+	log.InfoContext(ctx, fmt.Sprintf("Successfully saved snapshot for city: %s", data.City))
+	return nil
+}
+
+// Load returns the last good snapshot for a city, if one was saved.
+func Load(ctx context.Context, city string) (db.WeatherData, bool, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket()),
+		Key:    aws.String(objectKey(city)),
+	}
+
+	result, err := newClient().GetObjectWithContext(ctx, input)
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return db.WeatherData{}, false, nil
+		}
+		log.ErrorContext(ctx, fmt.Sprintf("Error reading snapshot from S3: %v", err))
+		return db.WeatherData{}, false, err
+	}
+	defer result.Body.Close()
+
+	body, err := io.ReadAll(result.Body)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error reading snapshot body: %v", err))
+		return db.WeatherData{}, false, err
+	}
+
+	var data db.WeatherData
+	if err := json.Unmarshal(body, &data); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error unmarshalling snapshot: %v", err))
+		return db.WeatherData{}, false, err
+	}
+
+	return data, true, nil
+}
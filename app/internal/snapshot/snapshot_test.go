@@ -0,0 +1,80 @@
+package snapshot
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"weather-lambda/internal/db"
+)
+
+// fakeS3 serves PutObject and GetObject from memory in place of the S3 API.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+}
+
+func (f *fakeS3) RoundTrip(r *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := r.URL.Host + r.URL.Path
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[key] = string(body)
+		return s3Response(http.StatusOK, ""), nil
+	case http.MethodGet:
+		if body, ok := f.objects[key]; ok {
+			return s3Response(http.StatusOK, body), nil
+		}
+		return s3Response(http.StatusNotFound, `<Error><Code>NoSuchKey</Code><Message>missing</Message></Error>`), nil
+	}
+	return s3Response(http.StatusMethodNotAllowed, ""), nil
+}
+
+func s3Response(status int, body string) *http.Response {
+	return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func useFakeS3(t *testing.T) *fakeS3 {
+	t.Helper()
+	t.Setenv("SNAPSHOT_BUCKET", "snapshots-test")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CA_BUNDLE", "")
+
+	fake := &fakeS3{objects: map[string]string{}}
+	original := http.DefaultTransport
+	http.DefaultTransport = fake
+	t.Cleanup(func() { http.DefaultTransport = original })
+	return fake
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	useFakeS3(t)
+	ctx := context.Background()
+	saved := db.WeatherData{City: "Lisbon", Temperature: 19.5, Humidity: 70, Time: "2024-01-01T00:00:00Z"}
+
+	if err := Save(ctx, saved); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, found, err := Load(ctx, "lisbon")
+	if err != nil || !found {
+		t.Fatalf("Load = found %v, err %v", found, err)
+	}
+	if loaded.City != saved.City || loaded.Temperature != saved.Temperature || loaded.Time != saved.Time {
+		t.Errorf("Load = %+v, want %+v", loaded, saved)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	useFakeS3(t)
+	if _, found, err := Load(context.Background(), "Nowhere"); found || err != nil {
+		t.Errorf("Load = found %v, err %v; want not found without error", found, err)
+	}
+}
@@ -0,0 +1,30 @@
+package weather
+
+var AirQualityFields = []string{
+	"particulateMatter25",
+	"particulateMatter10",
+	"pollutantO3",
+	"pollutantNO2",
+	"pollutantCO",
+	"pollutantSO2",
+}
+
+// AQICategory labels a PM2.5 concentration (μg/m³) using the US EPA breakpoints.
+func AQICategory(pm25 float64) string {
+	switch {
+	case pm25 < 0:
+		return "Unknown"
+	case pm25 <= 12.0:
+		return "Good"
+	case pm25 <= 35.4:
+		return "Moderate"
+	case pm25 <= 55.4:
+		return "Unhealthy for Sensitive Groups"
+	case pm25 <= 150.4:
+		return "Unhealthy"
+	case pm25 <= 250.4:
+		return "Very Unhealthy"
+	default:
+		return "Hazardous"
+	}
+}
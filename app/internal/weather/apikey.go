@@ -0,0 +1,40 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"weather-lambda/internal/log"
+)
+
+// fetchWithKeys calls the upstream with WEATHER_API_KEY and, when that key is
+// refused with 401 or 403, retries once with WEATHER_API_KEY_SECONDARY. Both
+// keys stay valid while the primary is being rotated. Without a secondary
+// key the primary's result is returned as is.
+func fetchWithKeys(ctx context.Context, operation string, query string, out interface{}) ([]byte, error) {
+	primary := os.Getenv("WEATHER_API_KEY")
+	if primary == "" {
+		return nil, fmt.Errorf("WEATHER_API_KEY is required")
+	}
+
+	body, err := fetchOnce(ctx, operation, query, primary, out)
+	secondary := os.Getenv("WEATHER_API_KEY_SECONDARY")
+	if secondary == "" || !keyRejected(err) {
+		return body, err
+	}
+
+	log.WarnContext(ctx, fmt.Sprintf("Primary API key rejected (%v), retrying with secondary key", err))
+	body, err = fetchOnce(ctx, operation, query, secondary, out)
+	if err == nil {
+		log.InfoContext(ctx, "Upstream call succeeded with secondary API key")
+	}
+	return body, err
+}
+
+func keyRejected(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) &&
+		(statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden)
+}
@@ -0,0 +1,78 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFetchWithKeys(t *testing.T) {
+	tests := []struct {
+		name       string
+		secondary  string
+		status     map[string]int
+		wantKeys   []string
+		wantStatus int
+	}{
+		{"primary accepted", "secondary-key", nil, []string{"primary-key"}, 0},
+		{"primary 401, secondary succeeds", "secondary-key", map[string]int{"primary-key": 401}, []string{"primary-key", "secondary-key"}, 0},
+		{"primary 403, secondary succeeds", "secondary-key", map[string]int{"primary-key": 403}, []string{"primary-key", "secondary-key"}, 0},
+		{"both keys fail", "secondary-key", map[string]int{"primary-key": 401, "secondary-key": 401}, []string{"primary-key", "secondary-key"}, 401},
+		{"secondary refused differently", "secondary-key", map[string]int{"primary-key": 401, "secondary-key": 403}, []string{"primary-key", "secondary-key"}, 403},
+		{"no secondary key", "", map[string]int{"primary-key": 401}, []string{"primary-key"}, 401},
+		{"other errors are not retried", "secondary-key", map[string]int{"primary-key": 500}, []string{"primary-key"}, 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WEATHER_API_KEY", "primary-key")
+			t.Setenv("WEATHER_API_KEY_SECONDARY", tt.secondary)
+			var keys []string
+			original := http.DefaultTransport
+			http.DefaultTransport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				key := r.URL.Query().Get("apikey")
+				keys = append(keys, key)
+				status := http.StatusOK
+				if code, ok := tt.status[key]; ok {
+					status = code
+				}
+				return &http.Response{
+					StatusCode: status,
+					Header:     http.Header{"Content-Type": []string{"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(`{"data":{"time":"2024-01-01T00:00:00Z","values":{"temperature":21.5}}}`)),
+				}, nil
+			})
+			t.Cleanup(func() { http.DefaultTransport = original })
+
+			var out WeatherResponse
+			_, err := fetchWithKeys(context.Background(), opRealtime, "location=Toronto", &out)
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("keys tried = %v, want %v", keys, tt.wantKeys)
+			}
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("fetchWithKeys: %v", err)
+				}
+				if out.Data.Values.Temperature != 21.5 {
+					t.Errorf("temperature = %v, want 21.5", out.Data.Values.Temperature)
+				}
+				return
+			}
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.wantStatus {
+				t.Errorf("err = %v, want status %d", err, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestFetchWithKeysRequiresPrimary(t *testing.T) {
+	t.Setenv("WEATHER_API_KEY", "")
+	t.Setenv("WEATHER_API_KEY_SECONDARY", "secondary-key")
+	if _, err := fetchWithKeys(context.Background(), opRealtime, "location=Toronto", &WeatherResponse{}); err == nil {
+		t.Error("fetchWithKeys without WEATHER_API_KEY succeeded")
+	}
+}
@@ -0,0 +1,59 @@
+package weather
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"strings"
+)
+
+const contentTypeSnippetLength = 200
+
+// ErrUnexpectedContentType reports a 2xx upstream body that is not JSON,
+// typically a block page from a WAF or proxy in front of the upstream.
+var ErrUnexpectedContentType = errors.New("unexpected upstream content type")
+
+// ContentTypeError carries the offending Content-Type and the start of the
+// body, with the API key redacted, so the interference is obvious in logs.
+type ContentTypeError struct {
+	ContentType string
+	Snippet     string
+}
+
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf("%v %q: %s", ErrUnexpectedContentType, e.ContentType, e.Snippet)
+}
+
+func (e *ContentTypeError) Is(target error) bool {
+	return target == ErrUnexpectedContentType
+}
+
+// checkContentType rejects bodies whose Content-Type is not JSON before they
+// are decoded. UPSTREAM_CONTENT_TYPE selects the policy: lenient, the
+// default, accepts a missing header; strict requires one; off skips the check.
+func checkContentType(contentType string, body []byte, apiKey string) error {
+	policy := os.Getenv("UPSTREAM_CONTENT_TYPE")
+	if policy == "off" || (contentType == "" && policy != "strict") {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		return nil
+	}
+	return &ContentTypeError{ContentType: contentType, Snippet: snippet(body, apiKey)}
+}
+
+// snippet returns the start of body on one line with the API key redacted.
+func snippet(body []byte, apiKey string) string {
+	if apiKey != "" {
+		body = bytes.ReplaceAll(body, []byte(apiKey), []byte("REDACTED"))
+	}
+	text := strings.Join(strings.Fields(string(body)), " ")
+	if len(text) > contentTypeSnippetLength {
+		text = text[:contentTypeSnippetLength] + "..."
+	}
+	return text
+}
@@ -0,0 +1,71 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCheckContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		contentType string
+		wantErr     bool
+	}{
+		{"json", "", "application/json", false},
+		{"json with charset", "", "application/json; charset=utf-8", false},
+		{"json suffix", "", "application/problem+json", false},
+		{"html", "", "text/html; charset=utf-8", true},
+		{"plain text", "", "text/plain", true},
+		{"missing when lenient", "", "", false},
+		{"missing when strict", "strict", "", true},
+		{"html when off", "off", "text/html", false},
+		{"malformed", "", "application/json;;", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("UPSTREAM_CONTENT_TYPE", tt.policy)
+			err := checkContentType(tt.contentType, []byte(`<html></html>`), "test-key")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkContentType(%q) = %v, wantErr %v", tt.contentType, err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrUnexpectedContentType) {
+				t.Errorf("err = %v, want ErrUnexpectedContentType", err)
+			}
+		})
+	}
+}
+
+func TestFetchWeatherRejectsHTML(t *testing.T) {
+	t.Setenv("WEATHER_API_KEY", "test-key")
+	t.Setenv("UPSTREAM_CONTENT_TYPE", "")
+	original := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		// A proxy block page that echoes the request URL
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Content-Type": []string{"text/html"}},
+			Body:       io.NopCloser(strings.NewReader("<html>\n  <body>Access denied for " + r.URL.String() + "</body>\n</html>")),
+		}, nil
+	})
+	t.Cleanup(func() { http.DefaultTransport = original })
+
+	_, err := FetchWeather(context.Background(), FetchOptions{Location: "Toronto"})
+	var contentTypeErr *ContentTypeError
+	if !errors.As(err, &contentTypeErr) {
+		t.Fatalf("FetchWeather = %v, want a ContentTypeError", err)
+	}
+	if contentTypeErr.ContentType != "text/html" {
+		t.Errorf("ContentType = %q, want text/html", contentTypeErr.ContentType)
+	}
+	if strings.Contains(contentTypeErr.Snippet, "test-key") || !strings.Contains(contentTypeErr.Snippet, "apikey=REDACTED") {
+		t.Errorf("Snippet = %q, want the API key redacted", contentTypeErr.Snippet)
+	}
+	if strings.Contains(contentTypeErr.Snippet, "\n") {
+		t.Errorf("Snippet = %q, want one line", contentTypeErr.Snippet)
+	}
+}
@@ -0,0 +1,49 @@
+package weather
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Provider identifies this upstream on stored readings.
+const Provider = "tomorrow.io"
+
+const (
+	upstreamHost      = "https://api.tomorrow.io"
+	defaultAPIVersion = "v4"
+)
+
+// Upstream operations fetched by this package
+const (
+	opRealtime = "realtime"
+	opForecast = "forecast"
+)
+
+// operationPaths maps each operation to the env var that overrides its path
+// under the API version, and the path used when it is unset.
+var operationPaths = map[string]struct {
+	envVar   string
+	fallback string
+}{
+	opRealtime: {"UPSTREAM_REALTIME_PATH", "weather/realtime"},
+	opForecast: {"UPSTREAM_FORECAST_PATH", "weather/forecast"},
+}
+
+// endpointURL builds the URL for an operation from UPSTREAM_API_VERSION and
+// the operation's path, so a new API version can be adopted without code
+// changes. The query must already be escaped.
+func endpointURL(operation string, query string) string {
+	version := strings.Trim(os.Getenv("UPSTREAM_API_VERSION"), "/")
+	if version == "" {
+		version = defaultAPIVersion
+	}
+
+	path := operationPaths[operation]
+	segment := strings.Trim(os.Getenv(path.envVar), "/")
+	if segment == "" {
+		segment = path.fallback
+	}
+
+	return fmt.Sprintf("%s/%s/%s?%s", upstreamHost, version, segment, query)
+}
@@ -0,0 +1,89 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"weather-lambda/internal/log"
+)
+
+const MaxTimesteps = 3
+
+// Timesteps accepted by the forecast endpoint, mapped to the timeline
+// name tomorrow.io returns them under.
+var timelineNames = map[string]string{
+	"1m": "minutely",
+	"1h": "hourly",
+	"1d": "daily",
+}
+
+type ForecastInterval struct {
+	Time   string              `json:"time"`
+	Values map[string]*float64 `json:"values"`
+}
+
+type ForecastTimelines struct {
+	Minutely []ForecastInterval `json:"minutely"`
+	Hourly   []ForecastInterval `json:"hourly"`
+	Daily    []ForecastInterval `json:"daily"`
+}
+
+type ForecastResponse struct {
+	Timelines ForecastTimelines `json:"timelines"`
+	Location  WeatherLocation   `json:"location"`
+}
+
+// Timeline returns the intervals for a single timestep such as "1h".
+func (r ForecastResponse) Timeline(timestep string) []ForecastInterval {
+	switch timelineNames[timestep] {
+	case "minutely":
+		return r.Timelines.Minutely
+	case "hourly":
+		return r.Timelines.Hourly
+	case "daily":
+		return r.Timelines.Daily
+	}
+	return nil
+}
+
+// ParseTimesteps splits a comma-separated timesteps parameter, rejecting
+// unknown values and more than MaxTimesteps distinct entries.
+func ParseTimesteps(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return []string{"1h"}, nil
+	}
+
+	var timesteps []string
+	seen := map[string]bool{}
+	for _, timestep := range strings.Split(value, ",") {
+		timestep = strings.ToLower(strings.TrimSpace(timestep))
+		if _, ok := timelineNames[timestep]; !ok {
+			return nil, fmt.Errorf("unsupported timestep: %q", timestep)
+		}
+		if seen[timestep] {
+			continue
+		}
+		seen[timestep] = true
+		timesteps = append(timesteps, timestep)
+	}
+
+	if len(timesteps) > MaxTimesteps {
+		return nil, fmt.Errorf("at most %d timesteps are supported", MaxTimesteps)
+	}
+	return timesteps, nil
+}
+
+// FetchForecast requests every timestep in a single upstream call.
+func FetchForecast(ctx context.Context, city string, timesteps []string) (ForecastResponse, error) {
+	log.InfoContext(ctx, fmt.Sprintf("Fetching forecast for city: %s, timesteps: %s", city, strings.Join(timesteps, ",")))
+
+	var forecastResponse ForecastResponse
+	query := fmt.Sprintf("location=%s&timesteps=%s", city, strings.Join(timesteps, ","))
+	if _, err := fetchJSON(ctx, opForecast, query, &forecastResponse); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error fetching forecast: %v", err))
+		return ForecastResponse{}, err
+	}
+
+	log.InfoContext(ctx, fmt.Sprintf("Successfully fetched forecast for city: %s", city))
+	return forecastResponse, nil
+}
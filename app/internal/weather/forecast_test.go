@@ -0,0 +1,36 @@
+package weather
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTimesteps(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{"", []string{"1h"}, false},
+		{"  ", []string{"1h"}, false},
+		{"1d", []string{"1d"}, false},
+		{"1m,1h,1d", []string{"1m", "1h", "1d"}, false},
+		{" 1H , 1d ", []string{"1h", "1d"}, false},
+		{"1h,1h,1d", []string{"1h", "1d"}, false},
+		{"2h", nil, true},
+		{"1h,", nil, true},
+		{"1h;1d", nil, true},
+		{"hourly", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseTimesteps(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTimesteps(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
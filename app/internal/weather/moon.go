@@ -0,0 +1,22 @@
+package weather
+
+var MoonPhaseFields = []string{"moonPhase"}
+
+var moonPhaseLabels = []string{
+	"New Moon",
+	"Waxing Crescent",
+	"First Quarter",
+	"Waxing Gibbous",
+	"Full Moon",
+	"Waning Gibbous",
+	"Third Quarter",
+	"Waning Crescent",
+}
+
+// MoonPhaseLabel names a tomorrow.io moonPhase code (0-7).
+func MoonPhaseLabel(phase int) string {
+	if phase < 0 || phase >= len(moonPhaseLabels) {
+		return "Unknown"
+	}
+	return moonPhaseLabels[phase]
+}
@@ -0,0 +1,22 @@
+package weather
+
+import "testing"
+
+func TestMoonPhaseLabel(t *testing.T) {
+	tests := []struct {
+		phase int
+		want  string
+	}{
+		{0, "New Moon"},
+		{3, "Waxing Gibbous"},
+		{4, "Full Moon"},
+		{7, "Waning Crescent"},
+		{-1, "Unknown"},
+		{8, "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := MoonPhaseLabel(tt.phase); got != tt.want {
+			t.Errorf("MoonPhaseLabel(%d) = %q, want %q", tt.phase, got, tt.want)
+		}
+	}
+}
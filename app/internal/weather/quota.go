@@ -0,0 +1,89 @@
+package weather
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"weather-lambda/internal/log"
+	"weather-lambda/internal/metrics"
+)
+
+// Reset values above this are unix timestamps rather than seconds to wait
+const epochThreshold = 1_000_000_000
+
+// ErrQuotaBackoff marks the 429 returned locally while the quota is within
+// its reserve. No call was made, so it says nothing about upstream health.
+var ErrQuotaBackoff = errors.New("upstream quota reserved")
+
+// quota tracks the upstream rate-limit window last reported by tomorrow.io.
+// It is process-local, so each warm container learns it from its own calls.
+var quota struct {
+	mu        sync.Mutex
+	known     bool
+	remaining int
+	reset     time.Time
+}
+
+// recordQuota updates the tracked window from X-RateLimit-Remaining and
+// X-RateLimit-Reset. Responses without a usable remaining count are ignored.
+func recordQuota(header http.Header, now time.Time) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil || remaining < 0 {
+		return
+	}
+
+	quota.mu.Lock()
+	defer quota.mu.Unlock()
+	quota.known = true
+	quota.remaining = remaining
+	quota.reset = parseReset(header.Get("X-RateLimit-Reset"), now)
+	metrics.UpstreamQuota.Set(int64(remaining))
+}
+
+// parseReset accepts either seconds until the window resets or a unix
+// timestamp. A missing or invalid value yields the zero time.
+func parseReset(value string, now time.Time) time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}
+	}
+	if seconds > epochThreshold {
+		return time.Unix(seconds, 0)
+	}
+	return now.Add(time.Duration(seconds) * time.Second)
+}
+
+// checkQuota refuses a call locally while the remaining quota is at or below
+// UPSTREAM_QUOTA_RESERVE and the window has not reset, so the reserve is kept
+// for later and the upstream never returns a hard 429. The returned error
+// carries the time left in the window as its Retry-After.
+func checkQuota(now time.Time) error {
+	reserve := quotaReserve()
+	if reserve < 0 {
+		return nil
+	}
+
+	quota.mu.Lock()
+	defer quota.mu.Unlock()
+	if !quota.known || quota.remaining > reserve || !now.Before(quota.reset) {
+		return nil
+	}
+
+	wait := int(quota.reset.Sub(now).Seconds()) + 1
+	log.Warn(fmt.Sprintf("Upstream quota low (%d remaining), backing off for %ds", quota.remaining, wait))
+	return fmt.Errorf("%w: %w", ErrQuotaBackoff, &StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: strconv.Itoa(wait)})
+}
+
+// quotaReserve reads UPSTREAM_QUOTA_RESERVE. It defaults to 0, backing off
+// only once the quota is exhausted; a negative value disables the check.
+func quotaReserve() int {
+	reserve, err := strconv.Atoi(os.Getenv("UPSTREAM_QUOTA_RESERVE"))
+	if err != nil {
+		return 0
+	}
+	return reserve
+}
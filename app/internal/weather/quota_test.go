@@ -0,0 +1,56 @@
+package weather
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCheckQuotaBackoff(t *testing.T) {
+	t.Setenv("UPSTREAM_QUOTA_RESERVE", "5")
+	t.Cleanup(func() {
+		quota.mu.Lock()
+		defer quota.mu.Unlock()
+		quota.known = false
+	})
+
+	now := time.Now()
+	recordQuota(http.Header{"X-Ratelimit-Remaining": []string{"3"}, "X-Ratelimit-Reset": []string{"30"}}, now)
+
+	err := checkQuota(now)
+	if !errors.Is(err, ErrQuotaBackoff) {
+		t.Fatalf("err = %v, want ErrQuotaBackoff", err)
+	}
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("err = %v, want a 429 StatusError", err)
+	}
+	if wait, _ := strconv.Atoi(statusErr.RetryAfter); wait < 30 || wait > 31 {
+		t.Errorf("RetryAfter = %q, want about 30s", statusErr.RetryAfter)
+	}
+
+	if err := checkQuota(now.Add(time.Minute)); err != nil {
+		t.Errorf("err after the reset = %v, want nil", err)
+	}
+}
+
+func TestParseReset(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"30", now.Add(30 * time.Second)},
+		{"1700000060", time.Unix(1_700_000_060, 0)},
+		{"", time.Time{}},
+		{"-5", time.Time{}},
+		{"soon", time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := parseReset(tt.value, now); !got.Equal(tt.want) {
+			t.Errorf("parseReset(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package weather
+
+import (
+	"errors"
+	"net/http"
+	"os"
+)
+
+const maxRedirects = 10
+
+var errRedirectRefused = errors.New("refusing upstream redirect")
+
+// client is shared by every upstream call so redirects are always checked.
+var client = &http.Client{CheckRedirect: checkRedirect}
+
+// checkRedirect stops the API key in the query string reaching another host.
+// UPSTREAM_REDIRECTS=same-host, the default, follows redirects on the same
+// host only; strip-key also follows cross-host ones with the key removed;
+// none refuses every redirect.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errors.New("stopped after too many redirects")
+	}
+
+	policy := os.Getenv("UPSTREAM_REDIRECTS")
+	if policy == "none" {
+		return errRedirectRefused
+	}
+	if req.URL.Host == via[0].URL.Host {
+		return nil
+	}
+	if policy != "strip-key" {
+		return errRedirectRefused
+	}
+
+	query := req.URL.Query()
+	query.Del("apikey")
+	req.URL.RawQuery = query.Encode()
+	return nil
+}
@@ -0,0 +1,69 @@
+package weather
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRedirectPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       string
+		target       string
+		wantFollowed bool
+		wantKey      bool
+	}{
+		{"cross-host refused by default", "", "https://elsewhere.example/weather", false, false},
+		{"same host followed by default", "", "https://api.tomorrow.io/v4/weather/moved", true, true},
+		{"cross-host with key stripped", "strip-key", "https://elsewhere.example/weather", true, false},
+		{"same host refused under none", "none", "https://api.tomorrow.io/v4/weather/moved", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WEATHER_API_KEY", "test-key")
+			t.Setenv("UPSTREAM_REDIRECTS", tt.policy)
+
+			var redirected []*http.Request
+			original := http.DefaultTransport
+			http.DefaultTransport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				if r.URL.Path == "/v4/weather/realtime" {
+					// The redirect carries the original query, key included
+					return &http.Response{
+						StatusCode: http.StatusFound,
+						Header:     http.Header{"Location": []string{tt.target + "?" + r.URL.RawQuery}},
+						Body:       io.NopCloser(strings.NewReader("")),
+					}, nil
+				}
+				redirected = append(redirected, r)
+				return &http.Response{
+					StatusCode: 200,
+					Header:     http.Header{"Content-Type": []string{"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(`{"data":{"values":{"temperature":20}}}`)),
+				}, nil
+			})
+			t.Cleanup(func() { http.DefaultTransport = original })
+
+			_, err := FetchWeather(context.Background(), FetchOptions{Location: "redirect-town"})
+			if followed := len(redirected) > 0; followed != tt.wantFollowed {
+				t.Fatalf("redirect followed = %v, want %v (err %v)", followed, tt.wantFollowed, err)
+			}
+			if !tt.wantFollowed {
+				if err == nil {
+					t.Errorf("a refused redirect returned no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FetchWeather: %v", err)
+			}
+			for _, r := range redirected {
+				if hasKey := r.URL.Query().Has("apikey"); hasKey != tt.wantKey {
+					t.Errorf("apikey sent to %s = %v, want %v", r.URL.Host, hasKey, tt.wantKey)
+				}
+			}
+		})
+	}
+}
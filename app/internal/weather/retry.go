@@ -0,0 +1,101 @@
+package weather
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+)
+
+const defaultStreamRetries = 1
+
+// TruncatedBodyError reports an upstream body that ended before it was
+// complete. It is a network hiccup rather than bad data, so it is retriable.
+type TruncatedBodyError struct {
+	Err error
+}
+
+func (e *TruncatedBodyError) Error() string {
+	return "truncated upstream response: " + e.Err.Error()
+}
+
+func (e *TruncatedBodyError) Unwrap() error {
+	return e.Err
+}
+
+func IsRetriable(err error) bool {
+	var truncated *TruncatedBodyError
+	return errors.As(err, &truncated)
+}
+
+// classifyDecodeError treats a body holding the start of a JSON value that
+// ends early as a truncated stream. Any other decode error, including an
+// empty body or trailing garbage after a complete value, means the JSON is
+// malformed.
+func classifyDecodeError(body []byte, err error) error {
+	var value json.RawMessage
+	if errors.Is(json.NewDecoder(bytes.NewReader(body)).Decode(&value), io.ErrUnexpectedEOF) {
+		return &TruncatedBodyError{Err: err}
+	}
+	return err
+}
+
+// ErrMissingValues reports a well-formed body without data.values, which
+// would otherwise decode to readings of all zeros.
+var ErrMissingValues = errors.New("upstream response has no data.values")
+
+// checkValuesPresent rejects a realtime body whose values object is absent,
+// null or empty. Individual missing fields are still allowed.
+func checkValuesPresent(body []byte) error {
+	var probe struct {
+		Data struct {
+			Values map[string]json.RawMessage `json:"values"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return err
+	}
+	if len(probe.Data.Values) == 0 {
+		return ErrMissingValues
+	}
+	return nil
+}
+
+// populatedValues counts the non-null fields in a realtime body's values.
+func populatedValues(body []byte) int {
+	var probe struct {
+		Data struct {
+			Values map[string]json.RawMessage `json:"values"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return 0
+	}
+	populated := 0
+	for _, value := range probe.Data.Values {
+		if string(value) != "null" {
+			populated++
+		}
+	}
+	return populated
+}
+
+// minPopulatedValues reads UPSTREAM_MIN_FIELDS, the number of non-null values
+// below which a reading is degraded. Zero, the default, disables the check.
+func minPopulatedValues() int {
+	minimum, err := strconv.Atoi(os.Getenv("UPSTREAM_MIN_FIELDS"))
+	if err != nil || minimum < 0 {
+		return 0
+	}
+	return minimum
+}
+
+func streamRetries() int {
+	retries, err := strconv.Atoi(os.Getenv("UPSTREAM_STREAM_RETRIES"))
+	if err != nil || retries < 0 {
+		return defaultStreamRetries
+	}
+	return retries
+}
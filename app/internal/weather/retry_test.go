@@ -0,0 +1,97 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClassifyDecodeError(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantTruncated bool
+	}{
+		{"cut off in an object", `{"data":{"values":{"temperature":21`, true},
+		{"cut off in a string", `{"data":{"time":"2024-01-`, true},
+		{"cut off after a key", `{"data":`, true},
+		{"empty", ``, false},
+		{"whitespace", "  \n", false},
+		{"trailing garbage", `{"data":{}}garbage`, false},
+		{"second value", `{"data":{}} {}`, false},
+		{"invalid character", `{"data":nope}`, false},
+		{"html error page", `<html>Bad Gateway</html>`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out WeatherResponse
+			err := json.Unmarshal([]byte(tt.body), &out)
+			if err == nil {
+				t.Fatalf("body decoded without error")
+			}
+			if got := IsRetriable(classifyDecodeError([]byte(tt.body), err)); got != tt.wantTruncated {
+				t.Errorf("retriable = %v, want %v", got, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestFetchWeatherRetriesTruncatedBody(t *testing.T) {
+	t.Setenv("WEATHER_API_KEY", "test-key")
+	bodies := []string{
+		`{"data":{"time":"2024-01-01T00:00:00Z","values":{"temperature":`,
+		`{"data":{"time":"2024-01-01T00:00:00Z","values":{"temperature":21.5}}}`,
+	}
+	calls := 0
+	original := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(*http.Request) (*http.Response, error) {
+		body := bodies[min(calls, len(bodies)-1)]
+		calls++
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	})
+	t.Cleanup(func() { http.DefaultTransport = original })
+
+	response, err := FetchWeather(context.Background(), FetchOptions{Location: "retry-town"})
+	if err != nil {
+		t.Fatalf("FetchWeather: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+	if response.Data.Values.Temperature != 21.5 {
+		t.Errorf("temperature = %v, want 21.5", response.Data.Values.Temperature)
+	}
+}
+
+func TestCheckValuesPresent(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"values present", `{"data":{"values":{"temperature":20}}}`, nil},
+		{"some fields null", `{"data":{"values":{"temperature":20,"humidity":null}}}`, nil},
+		{"no values", `{"data":{"time":"2024-01-01T00:00:00Z"}}`, ErrMissingValues},
+		{"null values", `{"data":{"values":null}}`, ErrMissingValues},
+		{"empty values", `{"data":{"values":{}}}`, ErrMissingValues},
+		{"no data", `{}`, ErrMissingValues},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkValuesPresent([]byte(tt.body)); got != tt.want {
+				t.Errorf("checkValuesPresent = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"weather-lambda/internal/log"
+)
+
+const (
+	defaultSearchURL = "https://geocoding-api.open-meteo.com/v1/search"
+	maxCandidates    = 10
+)
+
+// Candidate is one place matching a searched name.
+type Candidate struct {
+	Name    string  `json:"name"`
+	Region  string  `json:"admin1"`
+	Country string  `json:"country"`
+	Lat     float64 `json:"latitude"`
+	Lon     float64 `json:"longitude"`
+}
+
+// SearchLocations lists places matching a name. tomorrow.io resolves a name
+// to a single place, so this uses a geocoding search service instead, set by
+// GEOCODE_SEARCH_URL and defaulting to Open-Meteo's. The name must already be
+// escaped.
+func SearchLocations(ctx context.Context, name string) ([]Candidate, error) {
+	searchURL := os.Getenv("GEOCODE_SEARCH_URL")
+	if searchURL == "" {
+		searchURL = defaultSearchURL
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s?name=%s&count=%d", searchURL, name, maxCandidates), nil)
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error making geocoding request: %v", err))
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &StatusError{StatusCode: resp.StatusCode, RetryAfter: resp.Header.Get("Retry-After")}
+	}
+
+	var results struct {
+		Results []Candidate `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error decoding geocoding results: %v", err))
+		return nil, err
+	}
+
+	log.InfoContext(ctx, fmt.Sprintf("Found %d location candidates for: %s", len(results.Results), name))
+	return results.Results, nil
+}
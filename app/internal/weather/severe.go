@@ -0,0 +1,47 @@
+package weather
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Default thresholds at or above which a reading is severe, in tomorrow.io's
+// metric units. Each can be overridden by the named env var.
+const (
+	defaultSevereWindGust      = 25.0 // m/s, SEVERE_WIND_GUST
+	defaultSevereRainIntensity = 8.0  // mm/hr, SEVERE_RAIN_INTENSITY
+	defaultSevereUVIndex       = 11.0 // SEVERE_UV_INDEX
+	defaultSevereFreezingRain  = 0.1  // mm/hr, SEVERE_FREEZING_RAIN
+)
+
+// Severity reports whether current conditions cross any severe-weather
+// threshold, with one reason per threshold crossed.
+func Severity(values WeatherDataValues) (bool, []string) {
+	checks := []struct {
+		name      string
+		value     float64
+		threshold float64
+	}{
+		{"wind gust", values.WindGust, threshold("SEVERE_WIND_GUST", defaultSevereWindGust)},
+		{"heavy rain", float64(values.RainIntensity), threshold("SEVERE_RAIN_INTENSITY", defaultSevereRainIntensity)},
+		{"extreme UV", float64(values.UVIndex), threshold("SEVERE_UV_INDEX", defaultSevereUVIndex)},
+		{"freezing rain", float64(values.FreezingRainIntensity), threshold("SEVERE_FREEZING_RAIN", defaultSevereFreezingRain)},
+	}
+
+	var reasons []string
+	for _, check := range checks {
+		if check.value >= check.threshold {
+			reasons = append(reasons, fmt.Sprintf("%s %g >= %g", check.name, check.value, check.threshold))
+		}
+	}
+	return len(reasons) > 0, reasons
+}
+
+func threshold(envVar string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(envVar), 64)
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
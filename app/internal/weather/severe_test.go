@@ -0,0 +1,37 @@
+package weather
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		values      WeatherDataValues
+		wantSevere  bool
+		wantReasons []string
+	}{
+		{"calm", nil, WeatherDataValues{WindGust: 5, UVIndex: 3}, false, nil},
+		{"wind gust", nil, WeatherDataValues{WindGust: 25}, true, []string{"wind gust 25 >= 25"}},
+		{"heavy rain", nil, WeatherDataValues{RainIntensity: 9}, true, []string{"heavy rain 9 >= 8"}},
+		{"extreme UV", nil, WeatherDataValues{UVIndex: 11}, true, []string{"extreme UV 11 >= 11"}},
+		{"freezing rain", nil, WeatherDataValues{FreezingRainIntensity: 1}, true, []string{"freezing rain 1 >= 0.1"}},
+		{"several", nil, WeatherDataValues{WindGust: 30, UVIndex: 12}, true, []string{"wind gust 30 >= 25", "extreme UV 12 >= 11"}},
+		{"lowered threshold", map[string]string{"SEVERE_WIND_GUST": "10"}, WeatherDataValues{WindGust: 12}, true, []string{"wind gust 12 >= 10"}},
+		{"raised threshold", map[string]string{"SEVERE_UV_INDEX": "15"}, WeatherDataValues{UVIndex: 12}, false, nil},
+		{"invalid override", map[string]string{"SEVERE_WIND_GUST": "-1"}, WeatherDataValues{WindGust: 20}, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			severe, reasons := Severity(tt.values)
+			if severe != tt.wantSevere || !reflect.DeepEqual(reasons, tt.wantReasons) {
+				t.Errorf("Severity = %v %q, want %v %q", severe, reasons, tt.wantSevere, tt.wantReasons)
+			}
+		})
+	}
+}
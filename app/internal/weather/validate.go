@@ -0,0 +1,79 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"weather-lambda/internal/feature"
+	"weather-lambda/internal/log"
+	"weather-lambda/internal/metrics"
+)
+
+// ErrImplausibleValues reports an upstream reading outside physically
+// plausible ranges, which usually means the upstream schema has drifted.
+var ErrImplausibleValues = errors.New("upstream response has implausible values")
+
+// plausibleRange bounds a reading; anything outside it is flagged.
+type plausibleRange struct {
+	field    string
+	min, max float64
+}
+
+// Feature flags are resolved once per container at cold start
+var features = feature.Current()
+
+// validateResponse checks a decoded realtime response when the
+// validate-upstream feature is on. Anomalies are logged and counted; with
+// VALIDATE_UPSTREAM=reject they also fail the fetch with ErrImplausibleValues.
+func validateResponse(ctx context.Context, response WeatherResponse, units string) error {
+	reject := os.Getenv("VALIDATE_UPSTREAM") == "reject"
+	if !features.Enabled("validate-upstream") && !reject {
+		return nil
+	}
+
+	anomalies := anomalies(response, units)
+	if len(anomalies) == 0 {
+		return nil
+	}
+
+	metrics.UpstreamAnomalies.Inc()
+	log.WarnContext(ctx, fmt.Sprintf("Upstream anomalies for %s: %s", response.Location.Name, strings.Join(anomalies, "; ")))
+	if reject {
+		return fmt.Errorf("%w: %s", ErrImplausibleValues, strings.Join(anomalies, "; "))
+	}
+	return nil
+}
+
+// anomalies lists every required field that is missing and every value
+// outside its plausible range. Temperatures are bounded in the requested units.
+func anomalies(response WeatherResponse, units string) []string {
+	var found []string
+	if response.Data.Time == "" {
+		found = append(found, "data.time is missing")
+	}
+
+	values := response.Data.Values
+	temperature := plausibleRange{"temperature", -90, 60}
+	if units == "imperial" {
+		temperature = plausibleRange{"temperature", -130, 140}
+	}
+	checks := []struct {
+		plausibleRange
+		value float64
+	}{
+		{temperature, values.Temperature},
+		{plausibleRange{"humidity", 0, 100}, float64(values.Humidity)},
+		{plausibleRange{"cloudCover", 0, 100}, float64(values.CloudCover)},
+		{plausibleRange{"precipitationProbability", 0, 100}, float64(values.PrecipitationProbability)},
+		{plausibleRange{"windSpeed", 0, 200}, values.WindSpeed},
+		{plausibleRange{"windDirection", 0, 360}, values.WindDirection},
+	}
+	for _, check := range checks {
+		if check.value < check.min || check.value > check.max {
+			found = append(found, fmt.Sprintf("%s %g outside %g..%g", check.field, check.value, check.min, check.max))
+		}
+	}
+	return found
+}
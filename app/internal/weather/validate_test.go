@@ -0,0 +1,79 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"weather-lambda/internal/feature"
+)
+
+func TestAnomalies(t *testing.T) {
+	plausible := WeatherDataValues{Temperature: 20, Humidity: 50, CloudCover: 10, PrecipitationProbability: 5, WindSpeed: 3, WindDirection: 180}
+	tests := []struct {
+		name   string
+		time   string
+		values func(WeatherDataValues) WeatherDataValues
+		units  string
+		want   []string
+	}{
+		{"plausible", "2024-03-01T12:00:00Z", nil, "metric", nil},
+		{"missing time", "", nil, "metric", []string{"data.time is missing"}},
+		{"too hot in metric", "2024-03-01T12:00:00Z", func(v WeatherDataValues) WeatherDataValues { v.Temperature = 70; return v }, "metric", []string{"temperature 70 outside -90..60"}},
+		{"hot but plausible in imperial", "2024-03-01T12:00:00Z", func(v WeatherDataValues) WeatherDataValues { v.Temperature = 70; return v }, "imperial", nil},
+		{"too cold in imperial", "2024-03-01T12:00:00Z", func(v WeatherDataValues) WeatherDataValues { v.Temperature = -140; return v }, "imperial", []string{"temperature -140 outside -130..140"}},
+		{"humidity over 100", "2024-03-01T12:00:00Z", func(v WeatherDataValues) WeatherDataValues { v.Humidity = 101; return v }, "metric", []string{"humidity 101 outside 0..100"}},
+		{"negative cloud cover", "2024-03-01T12:00:00Z", func(v WeatherDataValues) WeatherDataValues { v.CloudCover = -1; return v }, "metric", []string{"cloudCover -1 outside 0..100"}},
+		{"precipitation probability over 100", "2024-03-01T12:00:00Z", func(v WeatherDataValues) WeatherDataValues { v.PrecipitationProbability = 120; return v }, "metric", []string{"precipitationProbability 120 outside 0..100"}},
+		{"negative wind speed", "2024-03-01T12:00:00Z", func(v WeatherDataValues) WeatherDataValues { v.WindSpeed = -2; return v }, "metric", []string{"windSpeed -2 outside 0..200"}},
+		{"wind direction over 360", "2024-03-01T12:00:00Z", func(v WeatherDataValues) WeatherDataValues { v.WindDirection = 400; return v }, "metric", []string{"windDirection 400 outside 0..360"}},
+		{"several at once", "", func(v WeatherDataValues) WeatherDataValues { v.Humidity = -5; v.WindSpeed = 250; return v }, "metric",
+			[]string{"data.time is missing", "humidity -5 outside 0..100", "windSpeed 250 outside 0..200"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := plausible
+			if tt.values != nil {
+				values = tt.values(values)
+			}
+			response := WeatherResponse{Data: WeatherData{Time: tt.time, Values: values}}
+			if got := anomalies(response, tt.units); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("anomalies = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	implausible := WeatherResponse{Data: WeatherData{Values: WeatherDataValues{Humidity: 150}}}
+	plausible := WeatherResponse{Data: WeatherData{Time: "2024-03-01T12:00:00Z", Values: WeatherDataValues{Humidity: 50}}}
+	tests := []struct {
+		name     string
+		enabled  string
+		mode     string
+		response WeatherResponse
+		wantErr  bool
+	}{
+		{"off ignores anomalies", "", "", implausible, false},
+		{"feature logs but serves", "validate-upstream", "", implausible, false},
+		{"reject fails the fetch", "", "reject", implausible, true},
+		{"reject passes plausible values", "", "reject", plausible, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VALIDATE_UPSTREAM", tt.mode)
+			original := features
+			features = feature.Parse(tt.enabled)
+			t.Cleanup(func() { features = original })
+
+			err := validateResponse(context.Background(), tt.response, "metric")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateResponse = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrImplausibleValues) {
+				t.Errorf("err = %v, want ErrImplausibleValues", err)
+			}
+		})
+	}
+}
@@ -1,11 +1,16 @@
 package weather
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
-	"os"
+	"strings"
+	"time"
 	"weather-lambda/internal/log"
+	"weather-lambda/internal/metrics"
 )
 
 type WeatherDataValues struct {
@@ -29,6 +34,17 @@ type WeatherDataValues struct {
 	WindDirection            float64     `json:"windDirection"`
 	WindGust                 float64     `json:"windGust"`
 	WindSpeed                float64     `json:"windSpeed"`
+
+	// Air quality fields are only returned when requested via fields
+	ParticulateMatter25 *float64 `json:"particulateMatter25,omitempty"`
+	ParticulateMatter10 *float64 `json:"particulateMatter10,omitempty"`
+	PollutantO3         *float64 `json:"pollutantO3,omitempty"`
+	PollutantNO2        *float64 `json:"pollutantNO2,omitempty"`
+	PollutantCO         *float64 `json:"pollutantCO,omitempty"`
+	PollutantSO2        *float64 `json:"pollutantSO2,omitempty"`
+
+	// MoonPhase is only returned when requested via fields
+	MoonPhase *int `json:"moonPhase,omitempty"`
 }
 
 type WeatherData struct {
@@ -46,42 +62,136 @@ type WeatherLocation struct {
 type WeatherResponse struct {
 	Data     WeatherData     `json:"data"`
 	Location WeatherLocation `json:"location"`
+
+	// Raw is the upstream body as received, with the API key redacted
+	Raw json.RawMessage `json:"-"`
+
+	// Degraded is set when fewer than UPSTREAM_MIN_FIELDS values are populated
+	Degraded bool `json:"-"`
 }
 
-func FetchWeather(city string) (WeatherResponse, error) {
-	apiKey := os.Getenv("WEATHER_API_KEY")
+// StatusError reports a non-2xx response from the upstream API.
+type StatusError struct {
+	StatusCode int
+
+	// RetryAfter is the upstream Retry-After header, if any
+	RetryAfter string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("received response with status code: %d", e.StatusCode)
+}
+
+// FetchOptions describes a current-conditions request. The zero value of
+// each optional field leaves the upstream default in place.
+type FetchOptions struct {
+	// Location is a city name or "lat,lon", already escaped
+	Location string
+
+	// Units is "metric" or "imperial"; empty uses the upstream default, metric
+	Units string
+
+	// Fields, such as AirQualityFields, are requested in addition to the defaults
+	Fields []string
+}
+
+// FetchWeatherByCity fetches the default current conditions for a city.
+func FetchWeatherByCity(ctx context.Context, city string) (WeatherResponse, error) {
+	return FetchWeather(ctx, FetchOptions{Location: city})
+}
+
+// FetchWeather fetches the current conditions described by opts.
+func FetchWeather(ctx context.Context, opts FetchOptions) (WeatherResponse, error) {
+	city := opts.Location
+	log.InfoContext(ctx, fmt.Sprintf("Fetching weather data for city: %s", city))
+
+	query := "location=" + city
+	if opts.Units != "" {
+		query += "&units=" + opts.Units
+	}
+	if len(opts.Fields) > 0 {
+		query += "&fields=" + strings.Join(opts.Fields, ",")
+	}
+
+	var weatherResponse WeatherResponse
+	raw, err := fetchJSON(ctx, opRealtime, query, &weatherResponse)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error fetching weather data: %v", err))
+		return WeatherResponse{}, err
+	}
+	if err := checkValuesPresent(raw); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error fetching weather data: %v", err))
+		return WeatherResponse{}, err
+	}
+	if err := validateResponse(ctx, weatherResponse, opts.Units); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error fetching weather data: %v", err))
+		return WeatherResponse{}, err
+	}
+	weatherResponse.Raw = raw
+	if populated, minimum := populatedValues(raw), minPopulatedValues(); populated < minimum {
+		log.WarnContext(ctx, fmt.Sprintf("Degraded upstream response for city %s: %d of %d expected values", city, populated, minimum))
+		metrics.DegradedResponses.Inc()
+		weatherResponse.Degraded = true
+	}
+
+	log.InfoContext(ctx, fmt.Sprintf("Successfully fetched weather data for city: %s", city))
+	return weatherResponse, nil
+}
 
-	if apiKey == "" {
-		return WeatherResponse{}, fmt.Errorf("WEATHER_API_KEY is required")
+// fetchJSON calls a tomorrow.io operation and decodes the JSON body into out.
+// The query must already be escaped. The body is also returned with the API key redacted.
+// Bodies cut off mid-stream are retried up to UPSTREAM_STREAM_RETRIES times.
+func fetchJSON(ctx context.Context, operation string, query string, out interface{}) ([]byte, error) {
+	retries := streamRetries()
+	for attempt := 0; ; attempt++ {
+		body, err := fetchWithKeys(ctx, operation, query, out)
+		if err == nil || !IsRetriable(err) || attempt >= retries || ctx.Err() != nil {
+			return body, err
+		}
+		log.InfoContext(ctx, fmt.Sprintf("Retrying truncated upstream response (attempt %d): %v", attempt+1, err))
 	}
+}
 
-	url := fmt.Sprintf("https://api.tomorrow.io/v4/weather/realtime?location=%s&apikey=%s", city, apiKey)
+func fetchOnce(ctx context.Context, operation string, query string, apiKey string, out interface{}) ([]byte, error) {
+	if err := checkQuota(time.Now()); err != nil {
+		return nil, err
+	}
 
-	log.Info(fmt.Sprintf("Fetching weather data for city: %s", city))
+	url := endpointURL(operation, query+"&apikey="+apiKey)
 
-	req, _ := http.NewRequest("GET", url, nil)
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
 	req.Header.Add("Accept", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		log.Error(fmt.Sprintf("Error making HTTP request: %v", err))
-		return WeatherResponse{}, err
+		log.ErrorContext(ctx, fmt.Sprintf("Error making HTTP request: %v", err))
+		return nil, err
 	}
 	defer resp.Body.Close()
+	recordQuota(resp.Header, time.Now())
 
-	log.Info(fmt.Sprintf("Received response with status code: %d", resp.StatusCode))
+	log.InfoContext(ctx, fmt.Sprintf("Received response with status code: %d", resp.StatusCode))
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return WeatherResponse{}, fmt.Errorf("received response with status code: %d", resp.StatusCode)
+		return nil, &StatusError{StatusCode: resp.StatusCode, RetryAfter: resp.Header.Get("Retry-After")}
 	}
 
-	log.Info(fmt.Sprintf("Response: %+v", resp))
+	log.InfoContext(ctx, fmt.Sprintf("Response: %+v", resp))
 
-	var weatherResponse WeatherResponse
-	if err := json.NewDecoder(resp.Body).Decode(&weatherResponse); err != nil {
-		log.Error(fmt.Sprintf("Error decoding weather data: %v", err))
-		return WeatherResponse{}, err
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error reading weather data: %v", err))
+		return nil, &TruncatedBodyError{Err: err}
 	}
 
-	log.Info(fmt.Sprintf("Successfully fetched weather data for city: %s", city))
-	return weatherResponse, nil
+	if err := checkContentType(resp.Header.Get("Content-Type"), body, apiKey); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error decoding weather data: %v", err))
+		return nil, err
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		log.ErrorContext(ctx, fmt.Sprintf("Error decoding weather data: %v", err))
+		return nil, classifyDecodeError(body, err)
+	}
+
+	return bytes.ReplaceAll(body, []byte(apiKey), []byte("REDACTED")), nil
 }
@@ -0,0 +1,65 @@
+package weather
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// captureQuery answers every upstream call with a valid reading and records
+// the query of the last one.
+func captureQuery(t *testing.T) *url.Values {
+	t.Helper()
+	t.Setenv("WEATHER_API_KEY", "test-key")
+	var query url.Values
+	original := http.DefaultTransport
+	http.DefaultTransport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		query = r.URL.Query()
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"data":{"time":"2024-01-01T00:00:00Z","values":{"temperature":20}}}`)),
+		}, nil
+	})
+	t.Cleanup(func() { http.DefaultTransport = original })
+	return &query
+}
+
+func TestFetchWeatherOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		fetch      func(context.Context) (WeatherResponse, error)
+		wantUnits  string
+		wantFields string
+	}{
+		{"location only", func(ctx context.Context) (WeatherResponse, error) {
+			return FetchWeather(ctx, FetchOptions{Location: "Paris"})
+		}, "", ""},
+		{"by city", func(ctx context.Context) (WeatherResponse, error) {
+			return FetchWeatherByCity(ctx, "Paris")
+		}, "", ""},
+		{"all options", func(ctx context.Context) (WeatherResponse, error) {
+			return FetchWeather(ctx, FetchOptions{Location: "Paris", Units: "imperial", Fields: []string{"pollutantO3", "moonPhase"}})
+		}, "imperial", "pollutantO3,moonPhase"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := captureQuery(t)
+			if _, err := tt.fetch(context.Background()); err != nil {
+				t.Fatalf("fetch: %v", err)
+			}
+			if got := query.Get("location"); got != "Paris" {
+				t.Errorf("location = %q, want Paris", got)
+			}
+			if got := query.Get("units"); got != tt.wantUnits {
+				t.Errorf("units = %q, want %q", got, tt.wantUnits)
+			}
+			if got := query.Get("fields"); got != tt.wantFields {
+				t.Errorf("fields = %q, want %q", got, tt.wantFields)
+			}
+		})
+	}
+}
@@ -62,6 +62,11 @@ resource "aws_dynamodb_table" "weather_data" {
     name = "City"
     type = "S"
   }
+
+  ttl {
+    attribute_name = "ExpiresAt"
+    enabled        = true
+  }
 }
 
 resource "aws_lambda_function" "weather_app" {
@@ -74,9 +79,10 @@ resource "aws_lambda_function" "weather_app" {
 
   environment {
     variables = {
-      DB_TABLE_NAME   = aws_dynamodb_table.weather_data.name
-      WEATHER_API_KEY = var.WEATHER_API_KEY
-      VERSION         = var.VERSION
+      DB_TABLE_NAME             = aws_dynamodb_table.weather_data.name
+      WEATHER_API_KEY           = var.WEATHER_API_KEY
+      WEATHER_API_KEY_SECONDARY = var.WEATHER_API_KEY_SECONDARY
+      VERSION                   = var.VERSION
     }
   }
 }
@@ -3,6 +3,12 @@ variable "WEATHER_API_KEY" {
   type        = string
 }
 
+variable "WEATHER_API_KEY_SECONDARY" {
+  description = "Fallback Tomorrow.io API key used while the primary is rotated"
+  type        = string
+  default     = ""
+}
+
 variable "DB_TABLE_NAME" {
   description = "Name of the DynamoDB table to store weather data"
   type        = string